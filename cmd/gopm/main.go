@@ -1,11 +1,21 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/martin/go-pm/internal/cache"
 	"github.com/martin/go-pm/internal/commands"
 	"github.com/martin/go-pm/internal/config"
+	"github.com/martin/go-pm/internal/history"
+	"github.com/martin/go-pm/internal/projecttypes"
 )
 
 func main() {
@@ -21,6 +31,14 @@ func main() {
 		handleListCommand()
 	case "select":
 		handleSelectCommand()
+	case "run":
+		handleRunCommand()
+	case "get":
+		handleGetCommand()
+	case "config":
+		handleConfigCommand()
+	case "cache":
+		handleCacheCommand()
 	case "help":
 		showUsage()
 	default:
@@ -31,24 +49,35 @@ func main() {
 }
 
 func handleListCommand() {
-	// Check for format flag
+	// Check for format and origin flags
 	format := "default"
-	if len(os.Args) > 2 && os.Args[2] == "--format=fzf" {
-		format = "fzf"
+	showOrigin := false
+	strict := false
+	refresh := false
+	for _, arg := range os.Args[2:] {
+		switch arg {
+		case "--format=fzf":
+			format = "fzf"
+		case "--origin":
+			showOrigin = true
+		case "--strict":
+			strict = true
+		case "--refresh":
+			refresh = true
+		}
 	}
 
-	// Load config from discovery
-	cfg, err := config.LoadConfigFromDiscovery()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		os.Exit(1)
-	}
+	cfg := loadConfig(strict, refresh)
+	printWarnings(cfg)
 
 	// Generate command list
 	var cmdList []string
-	if format == "fzf" {
+	switch {
+	case format == "fzf":
 		cmdList = commands.FormatForFzf(cfg)
-	} else {
+	case showOrigin:
+		cmdList = commands.ListCommandsWithOrigin(cfg)
+	default:
 		cmdList = commands.ListCommands(cfg)
 	}
 
@@ -59,38 +88,307 @@ func handleListCommand() {
 }
 
 func handleSelectCommand() {
-	// Check for enhanced flag
-	enhanced := false
-	for _, arg := range os.Args[2:] {
-		if arg == "--enhanced" || arg == "-e" {
-			enhanced = true
-			break
+	cfg := loadConfig(hasFlag(os.Args[2:], "--strict"), hasFlag(os.Args[2:], "--refresh"))
+
+	// A location:command reference skips the interactive selection
+	var result *commands.SelectionResult
+	var err error
+	if ref := refArg(os.Args[2:]); ref != "" {
+		result, err = commands.GetRefExecutionDetails(cfg, ref)
+	} else {
+		result, err = selectCommand(cfg, hasEnhancedFlag(os.Args[2:]))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error with selection: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Output as JSON for shell script parsing
+	result.Directory = commands.AbsDirectory(cfg, result.Directory)
+	if err := commands.WriteSelectionJSON(os.Stdout, result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleGetCommand() {
+	flags := flag.NewFlagSet("get", flag.ExitOnError)
+	location := flags.String("location", "", "Location id, name or path")
+	command := flags.String("command", "", "Command text or parser key")
+	strict := flags.Bool("strict", false, "Fail when the config loads with warnings")
+	refresh := flags.Bool("refresh", false, "Parse commands again instead of using the cache")
+	flags.Parse(os.Args[2:])
+
+	ref := flags.Arg(0)
+	if ref != "" && (*location != "" || *command != "") {
+		fmt.Fprintln(os.Stderr, "Error: give either a location:command reference or --location and --command")
+		os.Exit(1)
+	}
+	if ref == "" && (*location == "" || *command == "") {
+		fmt.Fprintln(os.Stderr, "Error: both --location and --command are required")
+		os.Exit(1)
+	}
+
+	cfg := loadConfig(*strict, *refresh)
+
+	var result *commands.SelectionResult
+	var err error
+	if ref != "" {
+		result, err = commands.GetRefExecutionDetails(cfg, ref)
+	} else {
+		result, err = commands.GetExecutionDetails(cfg, *location, *command)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := commands.WriteSelectionJSON(os.Stdout, result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleConfigCommand() {
+	if len(os.Args) < 3 || os.Args[2] != "validate" {
+		fmt.Fprintln(os.Stderr, "Error: expected gopm config validate [FILE]")
+		os.Exit(1)
+	}
+
+	configPath := ""
+	if len(os.Args) > 3 {
+		configPath = os.Args[3]
+	} else {
+		var err error
+		configPath, err = config.FindConfigFile()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 	}
 
-	// Load config from discovery
-	cfg, err := config.LoadConfigFromDiscovery()
+	problems, err := config.Validate(configPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "%d problem(s) found\n", len(problems))
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "%s is valid\n", configPath)
+}
+
+func handleCacheCommand() {
+	if len(os.Args) < 3 || os.Args[2] != "clear" {
+		fmt.Fprintln(os.Stderr, "Error: expected gopm cache clear")
+		os.Exit(1)
+	}
+
+	commandCache, err := cache.OpenDefault()
+	if err == nil {
+		err = commandCache.Clear()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Cleared %s\n", commandCache.Dir())
+}
+
+func handleRunCommand() {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	enhanced := flags.Bool("enhanced", false, "Use the enhanced TUI for selection")
+	flags.BoolVar(enhanced, "e", false, "Shorthand for --enhanced")
+	all := flags.Bool("all", false, "Run the command in every location that has it")
+	filter := flags.String("filter", "", "Only run in locations whose name matches this glob")
+	projectType := flags.String("type", "", "Only run in locations of this project type")
+	concurrency := flags.Int("parallel", runtime.NumCPU(), "Maximum number of commands running at once")
+	flags.IntVar(concurrency, "j", runtime.NumCPU(), "Shorthand for --parallel")
+	failFast := flags.Bool("fail-fast", false, "Stop remaining commands after the first failure")
+	strict := flags.Bool("strict", false, "Fail when the config loads with warnings")
+	refresh := flags.Bool("refresh", false, "Parse commands again instead of using the cache")
+
+	// Allow the command key before the flags: gopm run test --all
+	args := os.Args[2:]
+	key := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		key = args[0]
+		args = args[1:]
+	}
+	flags.Parse(args)
+	if key == "" && flags.NArg() > 0 {
+		key = flags.Arg(0)
+	}
+
+	// Without --all, --filter or --type the argument is a location:command reference
+	fanOut := *all || *filter != "" || *projectType != ""
+	if key == "" && fanOut {
+		fmt.Fprintln(os.Stderr, "Error: --all, --filter and --type require a command key")
 		os.Exit(1)
 	}
 
-	// Run fzf selection (enhanced or regular)
+	cfg := loadConfig(*strict, *refresh)
+
+	if fanOut {
+		runInLocations(cfg, key, commands.TargetFilter{Name: *filter, Type: *projectType}, *concurrency, *failFast)
+		return
+	}
+
 	var result *commands.SelectionResult
-	if enhanced {
-		result, err = commands.RunEnhancedFzf(cfg)
+	var err error
+	if key != "" {
+		result, err = commands.GetRefExecutionDetails(cfg, key)
 	} else {
-		result, err = commands.RunFzf(cfg)
+		result, err = selectCommand(cfg, *enhanced)
 	}
-
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error with selection: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Output as JSON for shell script parsing
-	fmt.Printf(`{"directory":"%s","command":"%s"}`, result.Directory, result.Command)
+	directory, err := commands.ResolveDirectory(cfg, result.Directory)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	result.Directory = directory
+
+	// Show what we're about to do
+	fmt.Fprintf(os.Stderr, "Running: %s\n", result.Command)
+	fmt.Fprintf(os.Stderr, "In: %s\n\n", result.Directory)
+
+	recordHistory(result)
+
+	code, err := commands.Execute(result, commands.ExecOptions{Shell: commands.ResolveShell(cfg)})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running command: %v\n", err)
+	}
+	os.Exit(code)
+}
+
+// recordHistory remembers the commands run for frecency sorting
+func recordHistory(results ...*commands.SelectionResult) {
+	err := history.UpdateDefault(func(commandHistory *history.History) {
+		now := time.Now()
+		for _, result := range results {
+			commandHistory.Record(result.Directory, result.Command, now)
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record history: %v\n", err)
+	}
+}
+
+// runInLocations runs a command key in every matching location and exits with the overall status
+func runInLocations(cfg *config.Config, key string, filter commands.TargetFilter, concurrency int, failFast bool) {
+	targets, err := commands.FindTargets(cfg, key, filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	recordHistory(targets...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	results := commands.RunParallel(ctx, targets, commands.ParallelOptions{
+		Concurrency: concurrency,
+		FailFast:    failFast,
+		Shell:       commands.ResolveShell(cfg),
+		Color:       isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "",
+	})
+
 	fmt.Println()
+	commands.WriteSummary(os.Stdout, results)
+
+	for _, result := range results {
+		if result.Failed() || result.Skipped {
+			stop()
+			os.Exit(1)
+		}
+	}
+}
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// loadConfig loads the nearest config file, keeping parsed commands in the cache.
+// With refresh, cached commands are parsed again. With strict, loading with
+// warnings prints them and fails.
+func loadConfig(strict, refresh bool) *config.Config {
+	if commandCache, err := cache.OpenDefault(); err == nil {
+		commandCache.Refresh = refresh
+		projecttypes.SetCommandCache(commandCache)
+	}
+
+	// Ctrl-C while parsers run stops them instead of leaving them behind
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	cfg, err := config.LoadConfigFromDiscoveryContext(ctx)
+	stop()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	if strict && len(cfg.Warnings) > 0 {
+		printWarnings(cfg)
+		fmt.Fprintf(os.Stderr, "Error: config loaded with %d warning(s) in strict mode\n", len(cfg.Warnings))
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// printWarnings prints the warnings of loading the config to stderr
+func printWarnings(cfg *config.Config) {
+	for _, warning := range cfg.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
+
+// refArg returns the first argument that is not a flag, the location:command
+// reference of select
+func refArg(args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
+}
+
+// hasEnhancedFlag reports whether the enhanced TUI was requested
+func hasEnhancedFlag(args []string) bool {
+	return hasFlag(args, "--enhanced") || hasFlag(args, "-e")
+}
+
+// hasFlag reports whether a boolean flag was given
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == name {
+			return true
+		}
+	}
+	return false
+}
+
+// selectCommand runs fzf selection (enhanced or regular)
+func selectCommand(cfg *config.Config, enhanced bool) (*commands.SelectionResult, error) {
+	if enhanced {
+		return commands.RunEnhancedFzf(cfg)
+	}
+	return commands.RunFzf(cfg)
 }
 
 func showUsage() {
@@ -102,13 +400,47 @@ func showUsage() {
 	fmt.Println("COMMANDS:")
 	fmt.Println("    list                     List all available location:command pairs")
 	fmt.Println("    list --format=fzf        List commands in fzf format")
+	fmt.Println("    list --origin            Prefix each command with the config file it came from")
 	fmt.Println("    select                   Interactive command selection with fzf")
 	fmt.Println("    select --enhanced        Enhanced TUI selection with location filtering")
+	fmt.Println("    select <ref>             Print the selection for a reference without prompting")
+	fmt.Println("    run                      Select a command and execute it")
+	fmt.Println("    run --enhanced           Select with the enhanced TUI and execute")
+	fmt.Println("    run <ref>                Execute the command of a reference")
+	fmt.Println("    run <key> --all          Run a command in every location that has it")
+	fmt.Println("        [--filter=GLOB] [--type=TYPE] [--parallel=N] [--fail-fast]")
+	fmt.Println("    get <ref>")
+	fmt.Println("    get --location=X --command=Y")
+	fmt.Println("                             Print execution details for a command as JSON")
+	fmt.Println("    cache clear              Remove the cached commands of every location")
+	fmt.Println("    config validate [FILE]   Check the config for unknown keys, invalid types, missing")
+	fmt.Println("                             directories and lint warnings; exits 1 on any problem")
 	fmt.Println("    help                     Show this help message")
 	fmt.Println()
+	fmt.Println("OPTIONS:")
+	fmt.Println("    --strict                 Fail instead of warning when part of the config cannot")
+	fmt.Println("                             be loaded, e.g. a parser fails (list, select, run, get)")
+	fmt.Println("    --refresh                Parse commands again instead of using the cache in")
+	fmt.Println("                             ~/.cache/gopm (list, select, run, get)")
+	fmt.Println()
+	fmt.Println("REFERENCES:")
+	fmt.Println("    A reference <ref> is location:command, as printed by gopm list. The location")
+	fmt.Println("    is an id, a name or a path and ends at the first colon; write \\: for a colon")
+	fmt.Println("    and \\\\ for a backslash in it. The command is a name, a parser key or a")
+	fmt.Println("    command line, and may contain colons. Locations sharing a name are listed")
+	fmt.Println("    by id, which is the id: field or the path relative to the config file.")
+	fmt.Println()
 	fmt.Println("EXAMPLES:")
 	fmt.Println("    gopm list")
 	fmt.Println("    gopm list --format=fzf")
 	fmt.Println("    gopm select")
 	fmt.Println("    gopm select --enhanced")
+	fmt.Println("    gopm run")
+	fmt.Println("    gopm run frontend:build")
+	fmt.Println("    gopm run 'tools\\:legacy:npm:lint'")
+	fmt.Println("    gopm run test --all --fail-fast")
+	fmt.Println("    gopm run build --type=npm --filter='web-*'")
+	fmt.Println("    gopm get frontend:build")
+	fmt.Println("    gopm get --location=frontend --command=build")
+	fmt.Println("    gopm config validate")
 }
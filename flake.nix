@@ -54,8 +54,6 @@
           
           # Set environment variables for Nix-specific paths
           export GOPM_BINARY="${gopm-bin}/bin/gopm"
-          export JQ_CMD="${pkgs.jq}/bin/jq"
-          export BASH_CMD="${pkgs.bash}/bin/bash"
           
           # Call the main function from core script
           gopm_main "$@"
@@ -67,9 +65,9 @@
           paths = [ gopm-bin gopm-wrapper ];
           buildInputs = [ pkgs.makeWrapper ];
           postBuild = ''
-            # Make sure jq is available at runtime
+            # Make sure a shell is available at runtime
             wrapProgram $out/bin/gopm \
-              --prefix PATH : ${pkgs.lib.makeBinPath [ pkgs.jq pkgs.bash ]}
+              --prefix PATH : ${pkgs.lib.makeBinPath [ pkgs.bash ]}
           '';
           
           meta = with pkgs.lib; {
@@ -104,7 +102,6 @@
         devShell = pkgs.mkShell {
           buildInputs = with pkgs; [
             go
-            jq
             gopls
             golangci-lint
             delve
@@ -120,7 +117,6 @@
             echo ""
             echo "Nix environment includes:"
             echo "  - Go ${pkgs.go.version}"
-            echo "  - jq ${pkgs.jq.version}"
             echo "  - gopls (Go Language Server)"
             echo "  - golangci-lint"
             echo "  - delve (Go debugger)"
@@ -38,26 +38,8 @@ show_usage() {
     echo "    and traversing up the directory tree until it finds one."
 }
 
-# Function to check dependencies
-check_dependencies() {
-    # Check if jq command exists (either direct or via JQ_CMD variable)
-    local jq_cmd="${JQ_CMD:-jq}"
-    if ! command -v "$jq_cmd" &> /dev/null; then
-        print_error "jq is required but not installed."
-        if [ -z "$JQ_CMD" ]; then
-            echo "Please install jq to use gopm:"
-            echo "  Ubuntu/Debian: sudo apt-get install jq"
-            echo "  macOS: brew install jq"
-            echo "  CentOS/RHEL: sudo yum install jq"
-        fi
-        exit 1
-    fi
-}
-
-# Function to run command interactively
-run_command() {
-    check_dependencies
-
+# Function to check that the gopm binary is available
+check_binary() {
     # GOPM_BINARY must be set by the wrapper
     if [ -z "$GOPM_BINARY" ]; then
         print_error "GOPM_BINARY environment variable not set."
@@ -69,82 +51,41 @@ run_command() {
         print_error "gopm binary not found at: $GOPM_BINARY"
         exit 1
     fi
+}
 
-    # Call gopm select to get the selection as JSON
-    print_info "Loading configuration and starting selection..."
-    if ! SELECTION_JSON=$("$GOPM_BINARY" select 2>/dev/null); then
-        print_error "Selection cancelled or failed."
-        exit 1
-    fi
-
-    # Check if we got valid JSON
-    if [ -z "$SELECTION_JSON" ]; then
-        print_error "No selection made."
-        exit 1
-    fi
-
-    # Parse JSON to extract directory and command
-    local jq_cmd="${JQ_CMD:-jq}"
-    DIRECTORY=$(echo "$SELECTION_JSON" | "$jq_cmd" -r '.directory')
-    COMMAND=$(echo "$SELECTION_JSON" | "$jq_cmd" -r '.command')
-
-    # Validate parsed values
-    if [ "$DIRECTORY" = "null" ] || [ "$COMMAND" = "null" ]; then
-        print_error "Failed to parse selection from gopm output."
-        exit 1
-    fi
-
-    # Check if directory exists
-    if [ ! -d "$DIRECTORY" ]; then
-        print_error "Directory '$DIRECTORY' does not exist."
-        exit 1
-    fi
-
-    # Show what we're about to do
-    print_info "Running: $COMMAND"
-    print_info "In: $DIRECTORY"
-    echo
+# Function to run command interactively
+run_command() {
+    check_binary
 
-    # Change to the directory and run the command
-    cd "$DIRECTORY"
-    
-    # Use BASH_CMD if set (for Nix), otherwise use bash
-    local bash_cmd="${BASH_CMD:-bash}"
-    exec "$bash_cmd" -c "$COMMAND"
+    # The binary handles selection, directory change and execution itself
+    exec "$GOPM_BINARY" run "$@"
 }
 
 # Function to list commands
 list_commands() {
-    # GOPM_BINARY must be set by the wrapper
-    if [ -z "$GOPM_BINARY" ]; then
-        print_error "GOPM_BINARY environment variable not set."
-        exit 1
-    fi
-
-    if [ ! -f "$GOPM_BINARY" ] || [ ! -x "$GOPM_BINARY" ]; then
-        print_error "gopm binary not found at: $GOPM_BINARY"
-        exit 1
-    fi
+    check_binary
 
-    "$GOPM_BINARY" list
+    "$GOPM_BINARY" list "$@"
 }
 
 # Main function - to be called by wrappers
 gopm_main() {
     case "${1:-run}" in
         run|"")
-            run_command
+            [ $# -gt 0 ] && shift
+            run_command "$@"
             ;;
         list)
-            list_commands
+            shift
+            list_commands "$@"
             ;;
         help|--help|-h)
             show_usage
             ;;
         *)
-            print_error "Unknown command '$1'"
-            show_usage
-            exit 1
+            # Everything else is handled by the binary directly
+            check_binary
+            exec "$GOPM_BINARY" "$@"
             ;;
     esac
 }
\ No newline at end of file
@@ -58,17 +58,13 @@ check_dependencies() {
         missing_deps+=("curl or wget")
     fi
     
-    if ! command_exists jq; then
-        missing_deps+=("jq")
-    fi
-    
     if [ ${#missing_deps[@]} -gt 0 ]; then
         print_error "Missing dependencies: ${missing_deps[*]}"
         echo
         echo "Please install the missing dependencies:"
-        echo "  Ubuntu/Debian: sudo apt-get install curl jq"
-        echo "  macOS: brew install curl jq"
-        echo "  CentOS/RHEL: sudo yum install curl jq"
+        echo "  Ubuntu/Debian: sudo apt-get install curl"
+        echo "  macOS: brew install curl"
+        echo "  CentOS/RHEL: sudo yum install curl"
         exit 1
     fi
 }
@@ -200,22 +196,8 @@ show_usage() {
     echo "    and traversing up the directory tree until it finds one."
 }
 
-# Function to check dependencies
-check_dependencies() {
-    if ! command -v jq &> /dev/null; then
-        print_error "jq is required but not installed."
-        echo "Please install jq to use gopm:"
-        echo "  Ubuntu/Debian: sudo apt-get install jq"
-        echo "  macOS: brew install jq"
-        echo "  CentOS/RHEL: sudo yum install jq"
-        exit 1
-    fi
-}
-
 # Function to run command interactively
 run_command() {
-    check_dependencies
-
     # Find the gopm binary
     GOPM_BINARY=$(find_gopm_binary)
     if [ $? -ne 0 ]; then
@@ -224,43 +206,8 @@ run_command() {
         exit 1
     fi
 
-    # Call gopm select to get the selection as JSON
-    print_info "Loading configuration and starting selection..."
-    if ! SELECTION_JSON=$("$GOPM_BINARY" select 2>/dev/null); then
-        print_error "Selection cancelled or failed."
-        exit 1
-    fi
-
-    # Check if we got valid JSON
-    if [ -z "$SELECTION_JSON" ]; then
-        print_error "No selection made."
-        exit 1
-    fi
-
-    # Parse JSON to extract directory and command
-    DIRECTORY=$(echo "$SELECTION_JSON" | jq -r '.directory')
-    COMMAND=$(echo "$SELECTION_JSON" | jq -r '.command')
-
-    # Validate parsed values
-    if [ "$DIRECTORY" = "null" ] || [ "$COMMAND" = "null" ]; then
-        print_error "Failed to parse selection from gopm output."
-        exit 1
-    fi
-
-    # Check if directory exists
-    if [ ! -d "$DIRECTORY" ]; then
-        print_error "Directory '$DIRECTORY' does not exist."
-        exit 1
-    fi
-
-    # Show what we're about to do
-    print_info "Running: $COMMAND"
-    print_info "In: $DIRECTORY"
-    echo
-
-    # Change to the directory and run the command
-    cd "$DIRECTORY"
-    exec bash -c "$COMMAND"
+    # The binary handles selection, directory change and execution itself
+    exec "$GOPM_BINARY" run "$@"
 }
 
 # Function to list commands
@@ -278,7 +225,8 @@ list_commands() {
 # Main script logic
 case "${1:-run}" in
     run|"")
-        run_command
+        [ $# -gt 0 ] && shift
+        run_command "$@"
         ;;
     list)
         list_commands
@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache stores values on disk. Each value records the files it was computed
+// from and is stale, and dropped, once one of them changes.
+type Cache struct {
+	dir string
+
+	// Refresh ignores stored values, so every value is computed and stored again
+	Refresh bool
+}
+
+// fileState is what a value remembers about one of its files
+type fileState struct {
+	Path    string    `json:"path"`
+	Missing bool      `json:"missing,omitempty"`
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Hash    string    `json:"hash"`
+}
+
+// entry is the content of a cache file
+type entry struct {
+	Key   string          `json:"key"`
+	Files []fileState     `json:"files"`
+	Value json.RawMessage `json:"value"`
+}
+
+// DefaultDir returns the cache directory, $XDG_CACHE_HOME/gopm or ~/.cache/gopm
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "gopm"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".cache", "gopm"), nil
+}
+
+// New returns a cache storing its values in dir
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// OpenDefault returns the cache in DefaultDir
+func OpenDefault() (*Cache, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return New(dir), nil
+}
+
+// Dir returns the directory of the cache
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Key hashes the parts identifying a value into a cache key
+func Key(parts ...any) string {
+	data, err := json.Marshal(parts)
+	if err != nil {
+		data = []byte(fmt.Sprint(parts...))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Get decodes the value stored for key into value. It reports false when there
+// is no value, or when it is stale and has been removed.
+func (c *Cache) Get(key string, value any) bool {
+	if c.Refresh {
+		return false
+	}
+
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	var stored entry
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Key != key || !fresh(stored.Files) {
+		os.Remove(path)
+		return false
+	}
+	if err := json.Unmarshal(stored.Value, value); err != nil {
+		os.Remove(path)
+		return false
+	}
+	return true
+}
+
+// Put stores value for key, valid as long as files are unchanged. Files that do
+// not exist are recorded as missing, so creating them makes the value stale.
+func (c *Cache) Put(key string, files []string, value any) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache value: %w", err)
+	}
+
+	stored := entry{Key: key, Value: encoded}
+	for _, file := range files {
+		state, err := stat(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		stored.Files = append(stored.Files, state)
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so concurrent runs never see a partial entry
+	tmp, err := os.CreateTemp(c.dir, ".entry-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every stored value
+func (c *Cache) Clear() error {
+	if err := os.RemoveAll(c.dir); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// path returns the file storing the value of key
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// fresh reports whether files are as they were recorded. A file whose
+// modification time or size changed is still fresh when its content did not.
+func fresh(files []fileState) bool {
+	for _, recorded := range files {
+		info, err := os.Stat(recorded.Path)
+		if err != nil {
+			if os.IsNotExist(err) && recorded.Missing {
+				continue
+			}
+			return false
+		}
+		if recorded.Missing {
+			return false
+		}
+		if info.ModTime().Equal(recorded.ModTime) && info.Size() == recorded.Size {
+			continue
+		}
+
+		hash, err := hashFile(recorded.Path)
+		if err != nil || hash != recorded.Hash {
+			return false
+		}
+	}
+	return true
+}
+
+// stat records the current state of a file
+func stat(path string) (fileState, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fileState{Path: path, Missing: true}, nil
+	}
+	if err != nil {
+		return fileState{}, err
+	}
+
+	hash, err := hashFile(path)
+	if err != nil {
+		return fileState{}, err
+	}
+	return fileState{Path: path, ModTime: info.ModTime(), Size: info.Size(), Hash: hash}, nil
+}
+
+// hashFile returns the SHA-256 of the content of a file
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacheGetPut(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "package.json")
+	missing := filepath.Join(tmpDir, "yarn.lock")
+	if err := os.WriteFile(file, []byte(`{"scripts": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(filepath.Join(tmpDir, "cache"))
+	key := Key("commands", "npm", tmpDir)
+
+	var value []string
+	if c.Get(key, &value) {
+		t.Fatal("Get() on an empty cache reported a value")
+	}
+
+	if err := c.Put(key, []string{file, missing}, []string{"build", "test"}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if !c.Get(key, &value) || !reflect.DeepEqual(value, []string{"build", "test"}) {
+		t.Fatalf("Get() = %v, expected the stored value", value)
+	}
+
+	// Touching a file without changing it keeps the value
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Get(key, &value) {
+		t.Error("Get() after touching a file reported no value")
+	}
+
+	c.Refresh = true
+	if c.Get(key, &value) {
+		t.Error("Get() with Refresh reported a value")
+	}
+	c.Refresh = false
+
+	if err := c.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if c.Get(key, &value) {
+		t.Error("Get() after Clear() reported a value")
+	}
+}
+
+func TestCacheStale(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(file, missing string) error
+	}{
+		{
+			name: "file changed",
+			change: func(file, missing string) error {
+				return os.WriteFile(file, []byte(`{"scripts": {"dev": "vite"}}`), 0644)
+			},
+		},
+		{
+			name: "file removed",
+			change: func(file, missing string) error {
+				return os.Remove(file)
+			},
+		},
+		{
+			name: "missing file created",
+			change: func(file, missing string) error {
+				return os.WriteFile(missing, nil, 0644)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			file := filepath.Join(tmpDir, "package.json")
+			missing := filepath.Join(tmpDir, "yarn.lock")
+			if err := os.WriteFile(file, []byte(`{"scripts": {}}`), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			c := New(filepath.Join(tmpDir, "cache"))
+			if err := c.Put("key", []string{file, missing}, "value"); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			if err := tt.change(file, missing); err != nil {
+				t.Fatal(err)
+			}
+
+			var value string
+			if c.Get("key", &value) {
+				t.Errorf("Get() = %q, expected a stale entry", value)
+			}
+			if _, err := os.Stat(c.path("key")); !os.IsNotExist(err) {
+				t.Error("Expected the stale entry to be removed")
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("a", 1) != Key("a", 1) {
+		t.Error("Key() is not deterministic")
+	}
+	if Key("a", 1) == Key("a", 2) {
+		t.Error("Key() ignores a part")
+	}
+}
+
+func TestDefaultDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/xdg")
+	dir, err := DefaultDir()
+	if err != nil || dir != "/tmp/xdg/gopm" {
+		t.Errorf("DefaultDir() = %q, %v", dir, err)
+	}
+
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("HOME", "/home/someone")
+	dir, err = DefaultDir()
+	if err != nil || dir != "/home/someone/.cache/gopm" {
+		t.Errorf("DefaultDir() = %q, %v", dir, err)
+	}
+}
@@ -13,7 +13,7 @@ func TestRunEnhancedFzf_ConfigParsing(t *testing.T) {
 			{
 				Name:     "test-project",
 				Location: "/test/path",
-				Commands: []string{"test command"},
+				Commands: []config.Command{{Run: "test command"}},
 			},
 		},
 	}
@@ -30,16 +30,16 @@ func TestPrepareCommandInfo_WithLocations(t *testing.T) {
 			{
 				Name:     "frontend",
 				Location: "/path/to/frontend",
-				Commands: []string{"npm start", "npm test"},
+				Commands: []config.Command{{Run: "npm start"}, {Run: "npm test"}},
 			},
 			{
 				Name:     "backend",
 				Location: "/path/to/backend",
-				Commands: []string{"go run main.go"},
+				Commands: []config.Command{{Run: "go run main.go"}},
 			},
 			{
 				Location: "/path/to/scripts",
-				Commands: []string{"./deploy.sh"},
+				Commands: []config.Command{{Run: "./deploy.sh"}},
 			},
 		},
 	}
@@ -52,10 +52,10 @@ func TestPrepareCommandInfo_WithLocations(t *testing.T) {
 
 	// Test that display names are formatted correctly
 	expectedDisplays := []string{
-		"[frontend] npm start",
-		"[frontend] npm test",
-		"[backend] go run main.go",
-		"[/path/to/scripts] ./deploy.sh",
+		"frontend: npm start",
+		"frontend: npm test",
+		"backend: go run main.go",
+		"/path/to/scripts: ./deploy.sh",
 	}
 
 	for i, expected := range expectedDisplays {
@@ -2,7 +2,6 @@ package commands
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 
 	fuzzyfinder "github.com/ktr0731/go-fuzzyfinder"
@@ -12,45 +11,46 @@ import (
 
 // SelectionResult represents the result of a user selection from fzf
 type SelectionResult struct {
-	Directory   string // The actual directory path where command should be executed
-	Command     string // The command to run
-	DisplayName string // The display name shown in fzf (for reference)
+	Directory   string            `json:"directory"`             // The actual directory path where command should be executed
+	Command     string            `json:"command"`               // The command to run
+	DisplayName string            `json:"display_name"`          // The display name shown in fzf (for reference)
+	ProjectType string            `json:"project_type"`          // The project type of the location, if any
+	Parser      string            `json:"parser"`                // The parser that produced the command
+	Name        string            `json:"name,omitempty"`        // The name of the command, if configured
+	Description string            `json:"description,omitempty"` // The description of the command, if configured
+	Env         map[string]string `json:"env,omitempty"`         // Environment variables added for the command
+	Shell       string            `json:"shell,omitempty"`       // Shell overriding the configured one
+	Tags        []string          `json:"tags,omitempty"`        // Labels of the command, if any
 }
 
-// ParseFzfSelection parses a fzf selection in format "location: command" and returns command and location
+// ParseFzfSelection parses a selection as printed by gopm list ("location:command",
+// see ParseRef) or by FormatForFzf ("[location] command") and returns command and location
 func ParseFzfSelection(selection string) (string, string, error) {
+	selection = strings.TrimSpace(selection)
 	if selection == "" {
 		return "", "", fmt.Errorf("empty selection")
 	}
 
-	// Match pattern: location: command
-	re := regexp.MustCompile(`^([^:]+):\s*(.+)$`)
-	matches := re.FindStringSubmatch(strings.TrimSpace(selection))
-
-	if len(matches) != 3 {
-		return "", "", fmt.Errorf("invalid selection format: expected 'location: command', got: %q", selection)
+	if strings.HasPrefix(selection, "[") {
+		location, command, ok := splitEscaped(selection[1:], ']')
+		command = strings.TrimSpace(command)
+		if !ok || location == "" || command == "" {
+			return "", "", fmt.Errorf("invalid selection format: expected '[location] command', got: %q", selection)
+		}
+		return command, location, nil
 	}
 
-	location := matches[1]
-	command := matches[2]
-
-	return command, location, nil
+	ref, err := ParseRef(selection)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid selection format: expected 'location:command', got: %q", selection)
+	}
+	return ref.Command, ref.Location, nil
 }
 
-// FindLocationByDisplayName finds a location in the config by its display name (name or location field)
+// FindLocationByDisplayName finds a location in the config by the location part of a
+// selection: its id, name or path, see FindLocation
 func FindLocationByDisplayName(cfg *config.Config, displayName string) (*config.Location, error) {
-	for _, location := range cfg.Locations {
-		// Check if display name matches the name field
-		if location.Name == displayName {
-			return &location, nil
-		}
-		// Check if display name matches the location field (when no name is set)
-		if location.Name == "" && location.Location == displayName {
-			return &location, nil
-		}
-	}
-
-	return nil, fmt.Errorf("location not found: %q", displayName)
+	return FindLocation(cfg, displayName)
 }
 
 // ProcessFzfSelection processes a fzf selection and returns a SelectionResult
@@ -67,11 +67,13 @@ func ProcessFzfSelection(cfg *config.Config, fzfSelection string) (*SelectionRes
 		return nil, err
 	}
 
-	return &SelectionResult{
-		Directory:   location.Location,
-		Command:     command,
-		DisplayName: displayName,
-	}, nil
+	entry, err := FindCommand(location, command)
+	if err != nil {
+		// Commands typed by hand still run in the location
+		entry = config.Command{Run: command}
+	}
+
+	return newSelectionResult(location, entry), nil
 }
 
 // CommandInfo holds information about a command for display
@@ -80,24 +82,28 @@ type CommandInfo struct {
 	Directory   string
 	Command     string
 	DisplayName string
+	ProjectType string
+	Parser      string
+	Entry       config.Command
 }
 
 // PrepareCommandInfo prepares command information for fuzzy finder
 func PrepareCommandInfo(cfg *config.Config) []CommandInfo {
 	var infos []CommandInfo
 
-	for _, location := range cfg.Locations {
-		displayName := location.Name
-		if displayName == "" {
-			displayName = location.Location
-		}
+	for i := range cfg.Locations {
+		location := &cfg.Locations[i]
+		displayName := location.DisplayName()
 
 		for _, command := range location.Commands {
 			info := CommandInfo{
-				Display:     fmt.Sprintf("%s: %s", displayName, command),
-				Directory:   location.Location,
-				Command:     command,
+				Display:     fmt.Sprintf("%s: %s", displayName, command.Title()),
+				Directory:   location.CommandDirectory(command),
+				Command:     command.Run,
 				DisplayName: displayName,
+				ProjectType: commandProjectType(location, command),
+				Parser:      command.Source(),
+				Entry:       command,
 			}
 			infos = append(infos, info)
 		}
@@ -126,8 +132,7 @@ func RunFzf(cfg *config.Config) (*SelectionResult, error) {
 				return ""
 			}
 			info := commandInfos[i]
-			preview := fmt.Sprintf("Directory: %s\nCommand: %s", info.Directory, info.Command)
-			return preview
+			return ui.CommandPreview(info.Directory, info.Entry)
 		}),
 	)
 
@@ -140,11 +145,13 @@ func RunFzf(cfg *config.Config) (*SelectionResult, error) {
 	}
 
 	selected := commandInfos[idx]
-	return &SelectionResult{
+	result := &SelectionResult{
 		Directory:   selected.Directory,
-		Command:     selected.Command,
 		DisplayName: selected.DisplayName,
-	}, nil
+		ProjectType: selected.ProjectType,
+	}
+	result.setCommand(selected.Entry)
+	return result, nil
 }
 
 // RunEnhancedFzf executes the enhanced fuzzy finder with location filtering support
@@ -155,9 +162,49 @@ func RunEnhancedFzf(cfg *config.Config) (*SelectionResult, error) {
 		return nil, err
 	}
 	// Convert ui.SelectionResult to commands.SelectionResult
-	return &SelectionResult{
-		Directory:   result.Directory,
-		Command:     result.Command,
-		DisplayName: result.DisplayName,
-	}, nil
+	location := cfg.FindLocationByID(result.LocationID)
+	if location == nil {
+		location, err = FindLocationByDisplayName(cfg, result.DisplayName)
+	}
+	if err != nil {
+		return &SelectionResult{
+			Directory:   result.Directory,
+			Command:     result.Command,
+			DisplayName: result.DisplayName,
+		}, nil
+	}
+	return newSelectionResult(location, result.Entry), nil
+}
+
+// newSelectionResult builds a SelectionResult for a command in a location
+func newSelectionResult(location *config.Location, command config.Command) *SelectionResult {
+	displayName := location.DisplayName()
+
+	result := &SelectionResult{
+		Directory:   location.CommandDirectory(command),
+		DisplayName: displayName,
+		ProjectType: commandProjectType(location, command),
+	}
+	result.setCommand(command)
+	return result
+}
+
+// setCommand copies the fields of a configured command into the result
+func (r *SelectionResult) setCommand(command config.Command) {
+	r.Command = command.Run
+	r.Parser = command.Source()
+	r.Name = command.Name
+	r.Description = command.Description
+	r.Env = command.Env
+	r.Shell = command.Shell
+	r.Tags = command.Tags
+}
+
+// commandProjectType returns the project type that produced a command, falling back
+// to the primary type of the location
+func commandProjectType(location *config.Location, command config.Command) string {
+	if command.Type != "" {
+		return command.Type
+	}
+	return location.Type
 }
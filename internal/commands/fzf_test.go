@@ -84,16 +84,16 @@ func TestFindLocationByDisplayName(t *testing.T) {
 			{
 				Name:     "frontend",
 				Location: "packages/frontend",
-				Commands: []string{"start", "build"},
+				Commands: []config.Command{{Run: "start"}, {Run: "build"}},
 			},
 			{
 				Location: "packages/backend",
-				Commands: []string{"run", "test"},
+				Commands: []config.Command{{Run: "run"}, {Run: "test"}},
 			},
 			{
 				Name:     "scripts",
 				Location: "scripts",
-				Commands: []string{"deploy.sh"},
+				Commands: []config.Command{{Run: "deploy.sh"}},
 			},
 		},
 	}
@@ -153,11 +153,11 @@ func TestSelectionResult(t *testing.T) {
 			{
 				Name:     "frontend",
 				Location: "packages/frontend",
-				Commands: []string{"start", "build"},
+				Commands: []config.Command{{Run: "start"}, {Run: "build"}},
 			},
 			{
 				Location: "packages/backend", 
-				Commands: []string{"run"},
+				Commands: []config.Command{{Run: "run"}},
 			},
 		},
 	}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/martin/go-pm/internal/config"
+)
+
+// FindLocation finds a location by its id, its name or its path.
+// Ids take precedence over names, and names over paths. A name shared by several
+// locations is an error listing their ids.
+func FindLocation(cfg *config.Config, ref string) (*config.Location, error) {
+	if location := cfg.FindLocationByID(ref); location != nil {
+		return location, nil
+	}
+
+	var named []*config.Location
+	for i := range cfg.Locations {
+		if cfg.Locations[i].Name != "" && cfg.Locations[i].Name == ref {
+			named = append(named, &cfg.Locations[i])
+		}
+	}
+	if len(named) == 1 {
+		return named[0], nil
+	}
+	if len(named) > 1 {
+		return nil, ambiguousLocationError(ref, named)
+	}
+
+	refPath := AbsDirectory(cfg, ref)
+	var found []*config.Location
+	for i := range cfg.Locations {
+		if AbsDirectory(cfg, cfg.Locations[i].Location) == refPath {
+			found = append(found, &cfg.Locations[i])
+		}
+	}
+	if len(found) == 1 {
+		return found[0], nil
+	}
+	if len(found) > 1 {
+		return nil, ambiguousLocationError(ref, found)
+	}
+
+	return nil, fmt.Errorf("location not found: %q", ref)
+}
+
+// FindCommand finds a command in a location by its command line, its name or its parser key.
+// Keys namespaced by project type (e.g. "npm:lint") may be given without the type
+// when only one type provides the key.
+func FindCommand(location *config.Location, ref string) (config.Command, error) {
+	for _, command := range location.Commands {
+		if command.Run == ref {
+			return command, nil
+		}
+	}
+
+	for _, command := range location.Commands {
+		if command.Matches(ref) {
+			return command, nil
+		}
+	}
+
+	var matches []config.Command
+	for _, command := range location.Commands {
+		if strings.HasSuffix(command.Key, ":"+ref) {
+			matches = append(matches, command)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) > 1 {
+		return config.Command{}, fmt.Errorf("command %q is ambiguous in location %q, prefix it with a project type", ref, location.Location)
+	}
+
+	return config.Command{}, fmt.Errorf("command %q not found in location %q", ref, location.Location)
+}
+
+// GetExecutionDetails looks up a location and command and returns everything needed to run it.
+// The directory of the result is absolute. See ResolveRef for a "location:command" reference.
+func GetExecutionDetails(cfg *config.Config, locationRef, commandRef string) (*SelectionResult, error) {
+	location, err := FindLocation(cfg, locationRef)
+	if err != nil {
+		return nil, err
+	}
+
+	command, err := FindCommand(location, commandRef)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newSelectionResult(location, command)
+	result.Directory = AbsDirectory(cfg, result.Directory)
+	return result, nil
+}
+
+// WriteSelectionJSON writes a selection as a single line of JSON
+func WriteSelectionJSON(w io.Writer, result *SelectionResult) error {
+	encoder := json.NewEncoder(w)
+	// Commands routinely contain <, > and &, keep them readable
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(result)
+}
+
+// AbsDirectory returns the absolute form of a location directory.
+// Relative directories are anchored at the directory of the config file.
+func AbsDirectory(cfg *config.Config, directory string) string {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	return cfg.ResolvePath(directory)
+}
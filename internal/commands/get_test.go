@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/martin/go-pm/internal/config"
+)
+
+func testGetConfig() *config.Config {
+	return &config.Config{
+		Dir: "/repo",
+		Locations: []config.Location{
+			{
+				Name:     "frontend",
+				Location: "packages/frontend",
+				Type:     "npm",
+				Commands: []config.Command{
+					{Run: "npm run build", Key: "build", Parser: "package_json_scripts"},
+					{Run: `node -e "console.log(\"hi\\n\")"`},
+					{
+						Name:        "storybook",
+						Run:         "npx storybook dev",
+						Description: "Component explorer",
+						Cwd:         "ui",
+						Env:         map[string]string{"PORT": "6006"},
+						Shell:       "bash",
+					},
+				},
+			},
+			{
+				Location: "scripts",
+				Commands: []config.Command{{Run: "./deploy.sh"}},
+			},
+			{
+				Name:     "mixed",
+				Location: "mixed",
+				Type:     "npm",
+				Types:    []string{"npm", "make"},
+				Commands: []config.Command{
+					{Run: "npm run lint", Key: "npm:lint", Parser: "package_json_scripts", Type: "npm"},
+					{Run: "make lint", Key: "make:lint", Parser: "parser_command", Type: "make"},
+					{Run: "make build", Key: "make:build", Parser: "parser_command", Type: "make"},
+				},
+			},
+		},
+	}
+}
+
+func TestGetExecutionDetails(t *testing.T) {
+	cfg := testGetConfig()
+
+	tests := []struct {
+		name        string
+		location    string
+		command     string
+		expected    SelectionResult
+		expectError bool
+	}{
+		{
+			name:     "location by name and command by key",
+			location: "frontend",
+			command:  "build",
+			expected: SelectionResult{
+				Directory:   filepath.Join("/repo", "packages", "frontend"),
+				Command:     "npm run build",
+				DisplayName: "frontend",
+				ProjectType: "npm",
+				Parser:      "package_json_scripts",
+			},
+		},
+		{
+			name:     "structured command by name",
+			location: "frontend",
+			command:  "storybook",
+			expected: SelectionResult{
+				Directory:   filepath.Join("/repo", "packages", "frontend", "ui"),
+				Command:     "npx storybook dev",
+				DisplayName: "frontend",
+				ProjectType: "npm",
+				Parser:      config.SourceConfig,
+				Name:        "storybook",
+				Description: "Component explorer",
+				Env:         map[string]string{"PORT": "6006"},
+				Shell:       "bash",
+			},
+		},
+		{
+			name:     "location by path and full command",
+			location: "scripts",
+			command:  "./deploy.sh",
+			expected: SelectionResult{
+				Directory:   filepath.Join("/repo", "scripts"),
+				Command:     "./deploy.sh",
+				DisplayName: "scripts",
+				Parser:      config.SourceConfig,
+			},
+		},
+		{
+			name:     "location by absolute path",
+			location: "/repo/packages/frontend",
+			command:  "npm run build",
+			expected: SelectionResult{
+				Directory:   filepath.Join("/repo", "packages", "frontend"),
+				Command:     "npm run build",
+				DisplayName: "frontend",
+				ProjectType: "npm",
+				Parser:      "package_json_scripts",
+			},
+		},
+		{
+			name:     "namespaced key",
+			location: "mixed",
+			command:  "make:lint",
+			expected: SelectionResult{
+				Directory:   filepath.Join("/repo", "mixed"),
+				Command:     "make lint",
+				DisplayName: "mixed",
+				ProjectType: "make",
+				Parser:      "parser_command",
+			},
+		},
+		{
+			name:     "unique key without namespace",
+			location: "mixed",
+			command:  "build",
+			expected: SelectionResult{
+				Directory:   filepath.Join("/repo", "mixed"),
+				Command:     "make build",
+				DisplayName: "mixed",
+				ProjectType: "make",
+				Parser:      "parser_command",
+			},
+		},
+		{
+			name:        "ambiguous key without namespace",
+			location:    "mixed",
+			command:     "lint",
+			expectError: true,
+		},
+		{
+			name:        "unknown location",
+			location:    "backend",
+			command:     "build",
+			expectError: true,
+		},
+		{
+			name:        "unknown command",
+			location:    "frontend",
+			command:     "deploy",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := GetExecutionDetails(cfg, tt.location, tt.command)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("GetExecutionDetails() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+			if !reflect.DeepEqual(*result, tt.expected) {
+				t.Errorf("GetExecutionDetails() = %+v, expected %+v", *result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteSelectionJSON(t *testing.T) {
+	cfg := testGetConfig()
+	command := cfg.Locations[0].Commands[1].Run
+
+	result, err := GetExecutionDetails(cfg, "frontend", command)
+	if err != nil {
+		t.Fatalf("GetExecutionDetails() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSelectionJSON(&buf, result); err != nil {
+		t.Fatalf("WriteSelectionJSON() error = %v", err)
+	}
+
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("Expected a single line of JSON, got %q", buf.String())
+	}
+
+	var decoded map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+	}
+
+	if decoded["command"] != command {
+		t.Errorf("command = %q, expected %q", decoded["command"], command)
+	}
+	if decoded["directory"] != filepath.Join("/repo", "packages", "frontend") {
+		t.Errorf("directory = %q, expected absolute path", decoded["directory"])
+	}
+	if decoded["display_name"] != "frontend" {
+		t.Errorf("display_name = %q, expected %q", decoded["display_name"], "frontend")
+	}
+	if decoded["project_type"] != "npm" {
+		t.Errorf("project_type = %q, expected %q", decoded["project_type"], "npm")
+	}
+	if decoded["parser"] != config.SourceConfig {
+		t.Errorf("parser = %q, expected %q", decoded["parser"], config.SourceConfig)
+	}
+}
@@ -2,30 +2,56 @@ package commands
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/martin/go-pm/internal/config"
 )
 
-// ListCommands returns a slice of location:command pairs
+// ListCommands returns a slice of location:command references, see ParseRef.
+// Locations sharing a name are listed by id. Named commands are listed by name.
 func ListCommands(cfg *config.Config) []string {
 	var commands []string
 	
 	for _, location := range cfg.Locations {
 		// Use name if available, otherwise use location path
-		displayName := location.Name
-		if displayName == "" {
-			displayName = location.Location
-		}
+		displayName := EscapeLocation(LocationRef(cfg, &location))
 		
 		// Add each command for this location
 		for _, command := range location.Commands {
-			commands = append(commands, fmt.Sprintf("%s:%s", displayName, command))
+			commands = append(commands, displayName+":"+command.Title())
 		}
 	}
 	
 	return commands
 }
 
+// ListCommandsWithOrigin returns the lines of ListCommands, each prefixed by the
+// config file the entry came from and a tab. The file is relative to the config
+// directory, so entries of the personal overlay show as .gopmrc.local.yaml.
+// Generated commands report the file of their location, and "-" when the location
+// was discovered rather than declared.
+func ListCommandsWithOrigin(cfg *config.Config) []string {
+	var commands []string
+
+	for _, location := range cfg.Locations {
+		displayName := EscapeLocation(LocationRef(cfg, &location))
+		for _, command := range location.Commands {
+			origin := command.Origin
+			if origin == "" {
+				origin = location.Source
+			}
+			if origin == "" {
+				origin = "-"
+			} else if rel, err := filepath.Rel(cfg.Dir, origin); err == nil {
+				origin = rel
+			}
+			commands = append(commands, origin+"\t"+displayName+":"+command.Title())
+		}
+	}
+
+	return commands
+}
+
 // FormatForFzf returns a slice of commands formatted for fzf selection
 // Format: [location-or-name] command
 func FormatForFzf(cfg *config.Config) []string {
@@ -33,14 +59,11 @@ func FormatForFzf(cfg *config.Config) []string {
 	
 	for _, location := range cfg.Locations {
 		// Use name if available, otherwise use location path
-		displayName := location.Name
-		if displayName == "" {
-			displayName = location.Location
-		}
+		displayName := EscapeLocation(LocationRef(cfg, &location))
 		
 		// Add each command for this location in fzf format
 		for _, command := range location.Commands {
-			commands = append(commands, fmt.Sprintf("[%s] %s", displayName, command))
+			commands = append(commands, fmt.Sprintf("[%s] %s", displayName, command.Title()))
 		}
 	}
 	
@@ -20,7 +20,7 @@ func TestListCommands(t *testing.T) {
 					{
 						Name:     "frontend",
 						Location: "packages/frontend",
-						Commands: []string{"start", "build", "test"},
+						Commands: []config.Command{{Run: "start"}, {Run: "build"}, {Run: "test"}},
 					},
 				},
 			},
@@ -37,12 +37,12 @@ func TestListCommands(t *testing.T) {
 					{
 						Name:     "frontend",
 						Location: "packages/frontend",
-						Commands: []string{"start", "build"},
+						Commands: []config.Command{{Run: "start"}, {Run: "build"}},
 					},
 					{
 						Name:     "backend",
 						Location: "packages/backend",
-						Commands: []string{"run", "test"},
+						Commands: []config.Command{{Run: "run"}, {Run: "test"}},
 					},
 				},
 			},
@@ -59,12 +59,12 @@ func TestListCommands(t *testing.T) {
 				Locations: []config.Location{
 					{
 						Location: "packages/frontend",
-						Commands: []string{"start", "build"},
+						Commands: []config.Command{{Run: "start"}, {Run: "build"}},
 					},
 					{
 						Name:     "backend",
 						Location: "packages/backend",
-						Commands: []string{"run"},
+						Commands: []config.Command{{Run: "run"}},
 					},
 				},
 			},
@@ -85,7 +85,7 @@ func TestListCommands(t *testing.T) {
 					{
 						Name:     "backend",
 						Location: "packages/backend",
-						Commands: []string{"run"},
+						Commands: []config.Command{{Run: "run"}},
 					},
 				},
 			},
@@ -93,6 +93,25 @@ func TestListCommands(t *testing.T) {
 				"backend:run",
 			},
 		},
+		{
+			name: "structured commands are listed by name without description",
+			cfg: &config.Config{
+				Locations: []config.Location{
+					{
+						Name:     "frontend",
+						Location: "packages/frontend",
+						Commands: []config.Command{
+							{Name: "storybook", Run: "npx storybook dev", Description: "Component explorer"},
+							{Run: "npm run lint", Description: "Lint sources"},
+						},
+					},
+				},
+			},
+			expected: []string{
+				"frontend:storybook",
+				"frontend:npm run lint",
+			},
+		},
 		{
 			name: "empty config",
 			cfg: &config.Config{
@@ -133,7 +152,7 @@ func TestFormatForFzf(t *testing.T) {
 					{
 						Name:     "frontend",
 						Location: "packages/frontend",
-						Commands: []string{"start", "build"},
+						Commands: []config.Command{{Run: "start"}, {Run: "build"}},
 					},
 				},
 			},
@@ -149,11 +168,11 @@ func TestFormatForFzf(t *testing.T) {
 					{
 						Name:     "frontend",
 						Location: "packages/frontend",
-						Commands: []string{"start"},
+						Commands: []config.Command{{Run: "start"}},
 					},
 					{
 						Location: "packages/backend",
-						Commands: []string{"run"},
+						Commands: []config.Command{{Run: "run"}},
 					},
 				},
 			},
@@ -195,11 +214,11 @@ func TestListCommandsOutput(t *testing.T) {
 			{
 				Name:     "frontend",
 				Location: "packages/frontend",
-				Commands: []string{"start", "build"},
+				Commands: []config.Command{{Run: "start"}, {Run: "build"}},
 			},
 			{
 				Location: "packages/backend",
-				Commands: []string{"run", "test"},
+				Commands: []config.Command{{Run: "run"}, {Run: "test"}},
 			},
 		},
 	}
@@ -244,4 +263,43 @@ packages/backend:test`,
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestListCommandsWithOrigin(t *testing.T) {
+	cfg := &config.Config{
+		Dir:       "/repo",
+		LocalPath: "/repo/.gopmrc.local.yaml",
+		Locations: []config.Location{
+			{
+				Name:   "api",
+				Source: "/repo/.gopmrc",
+				Commands: []config.Command{
+					{Run: "make build", Origin: "/repo/.gopmrc"},
+					{Run: "./seed.sh", Description: "Seed my database", Origin: "/repo/.gopmrc.local.yaml"},
+					{Run: "go test ./...", Key: "test", Parser: "go"},
+				},
+			},
+			{
+				Name:     "web",
+				Commands: []config.Command{{Run: "npm run dev", Key: "dev"}},
+			},
+		},
+	}
+
+	expected := []string{
+		".gopmrc\tapi:make build",
+		".gopmrc.local.yaml\tapi:./seed.sh",
+		".gopmrc\tapi:go test ./...",
+		"-\tweb:npm run dev",
+	}
+
+	result := ListCommandsWithOrigin(cfg)
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(expected), len(result), result)
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("Command[%d] = %q, expected %q", i, result[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/martin/go-pm/internal/config"
+)
+
+// prefixColors are the ANSI colors cycled through for location prefixes
+var prefixColors = []string{"36", "33", "32", "35", "34", "31", "96", "93", "92", "95", "94", "91"}
+
+// cancelGracePeriod is how long a canceled command gets to exit before it is killed
+const cancelGracePeriod = 5 * time.Second
+
+// TargetFilter selects the locations a command key is run in
+type TargetFilter struct {
+	Name string // Glob matched against the location display name (empty matches all)
+	Type string // Project type the location must have (empty matches all)
+}
+
+// ParallelOptions controls how a command is run across several locations
+type ParallelOptions struct {
+	Concurrency int       // Maximum number of commands running at once (defaults to the number of CPUs)
+	FailFast    bool      // Cancel remaining commands after the first failure
+	Shell       string    // Shell used to run the commands
+	Color       bool      // Color the location prefixes
+	Stdout      io.Writer // Defaults to os.Stdout
+	Stderr      io.Writer // Defaults to os.Stderr
+}
+
+// TaskResult is the outcome of running a command in one location
+type TaskResult struct {
+	Target   *SelectionResult
+	ExitCode int
+	Err      error
+	Duration time.Duration
+	Skipped  bool // The command never started because of fail-fast
+}
+
+// Failed reports whether the task ran and did not succeed
+func (r TaskResult) Failed() bool {
+	return !r.Skipped && (r.Err != nil || r.ExitCode != 0)
+}
+
+// FindTargets returns the command for key in every location that has it and matches the filter
+func FindTargets(cfg *config.Config, key string, filter TargetFilter) ([]*SelectionResult, error) {
+	if filter.Name != "" {
+		if _, err := path.Match(filter.Name, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", filter.Name, err)
+		}
+	}
+
+	var targets []*SelectionResult
+	for i := range cfg.Locations {
+		location := &cfg.Locations[i]
+		target := newSelectionResult(location, config.Command{})
+
+		if filter.Name != "" {
+			if matched, _ := path.Match(filter.Name, target.DisplayName); !matched {
+				continue
+			}
+		}
+		if filter.Type != "" && !location.HasType(filter.Type) {
+			continue
+		}
+
+		command, err := FindCommand(location, key)
+		if err != nil {
+			continue
+		}
+
+		target = newSelectionResult(location, command)
+		target.Directory = AbsDirectory(cfg, target.Directory)
+		targets = append(targets, target)
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no locations have command %q", key)
+	}
+
+	return targets, nil
+}
+
+// RunParallel runs each target with a concurrency limit, prefixing every output line with
+// the location name. Results are returned in the same order as targets.
+func RunParallel(ctx context.Context, targets []*SelectionResult, opts ParallelOptions) []TaskResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	shell := opts.Shell
+	if shell == "" {
+		shell = ResolveShell(nil)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Pad prefixes so output from different locations lines up
+	width := 0
+	for _, target := range targets {
+		if len(target.DisplayName) > width {
+			width = len(target.DisplayName)
+		}
+	}
+
+	var outputMu sync.Mutex
+	results := make([]TaskResult, len(targets))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		results[i] = TaskResult{Target: target, Skipped: true}
+
+		select {
+		case <-ctx.Done():
+			continue
+		case semaphore <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			<-semaphore
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, target *SelectionResult) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			prefix := fmt.Sprintf("%-*s | ", width, target.DisplayName)
+			if opts.Color {
+				prefix = fmt.Sprintf("\033[%sm%s\033[0m", prefixColors[i%len(prefixColors)], prefix)
+			}
+			out := &prefixWriter{w: stdout, mu: &outputMu, prefix: prefix}
+			errOut := &prefixWriter{w: stderr, mu: &outputMu, prefix: prefix}
+
+			result := runTask(ctx, shell, target, out, errOut)
+			out.Flush()
+			errOut.Flush()
+
+			results[i] = result
+			if opts.FailFast && result.Failed() {
+				cancel()
+			}
+		}(i, target)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// runTask runs a single target and records its outcome
+func runTask(ctx context.Context, shell string, target *SelectionResult, stdout, stderr io.Writer) TaskResult {
+	cmd := exec.CommandContext(ctx, commandShell(target, shell), "-c", target.Command)
+	cmd.Dir = target.Directory
+	cmd.Env = commandEnv(target)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Interrupt first so the command can clean up, kill it after the grace period
+	stopKill := setProcessGroup(cmd, cancelGracePeriod)
+	cmd.WaitDelay = cancelGracePeriod
+
+	start := time.Now()
+	err := cmd.Run()
+	stopKill()
+	result := TaskResult{Target: target, Duration: time.Since(start)}
+	result.ExitCode, result.Err = exitCode(err)
+	return result
+}
+
+// WriteSummary writes a pass/fail/duration table for the results
+func WriteSummary(w io.Writer, results []TaskResult) {
+	var passed, failed, skipped int
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tLOCATION\tDURATION\tEXIT")
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			skipped++
+			fmt.Fprintf(tw, "SKIP\t%s\t-\t-\n", result.Target.DisplayName)
+		case result.Failed():
+			failed++
+			exit := fmt.Sprintf("%d", result.ExitCode)
+			if result.Err != nil {
+				exit = result.Err.Error()
+			}
+			fmt.Fprintf(tw, "FAIL\t%s\t%s\t%s\n", result.Target.DisplayName, formatDuration(result.Duration), exit)
+		default:
+			passed++
+			fmt.Fprintf(tw, "PASS\t%s\t%s\t0\n", result.Target.DisplayName, formatDuration(result.Duration))
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d passed, %d failed, %d skipped\n", passed, failed, skipped)
+}
+
+// formatDuration rounds durations for display
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// prefixWriter prefixes every complete line written to it. Lines from
+// different writers sharing the same mutex never interleave.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    bytes.Buffer
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.buf.Write(data)
+
+	for {
+		line, err := p.buf.ReadBytes('\n')
+		if err != nil {
+			// Keep the partial line until it is completed
+			p.buf.Reset()
+			p.buf.Write(line)
+			break
+		}
+		if err := p.writeLine(line); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(data), nil
+}
+
+// Flush writes any trailing partial line
+func (p *prefixWriter) Flush() error {
+	if p.buf.Len() == 0 {
+		return nil
+	}
+	line := p.buf.String()
+	p.buf.Reset()
+	return p.writeLine([]byte(line))
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := io.WriteString(p.w, p.prefix+strings.TrimRight(string(line), "\r\n")+"\n")
+	return err
+}
@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/martin/go-pm/internal/config"
+)
+
+func testParallelConfig(dir string) *config.Config {
+	return &config.Config{
+		Dir: dir,
+		Locations: []config.Location{
+			{
+				Name:     "web-app",
+				Location: ".",
+				Type:     "npm",
+				Commands: []config.Command{{Run: "npm run test", Key: "test", Parser: "package_json_scripts"}},
+			},
+			{
+				Name:     "web-admin",
+				Location: ".",
+				Type:     "npm",
+				Commands: []config.Command{{Run: "npm run build", Key: "build", Parser: "package_json_scripts"}},
+			},
+			{
+				Name:     "api",
+				Location: ".",
+				Type:     "go",
+				Types:    []string{"go", "make"},
+				Commands: []config.Command{{Run: "go test ./...", Key: "test", Parser: "base_commands"}},
+			},
+		},
+	}
+}
+
+func TestFindTargets(t *testing.T) {
+	cfg := testParallelConfig(t.TempDir())
+
+	tests := []struct {
+		name        string
+		key         string
+		filter      TargetFilter
+		expected    []string
+		expectError bool
+	}{
+		{
+			name:     "all locations with key",
+			key:      "test",
+			expected: []string{"web-app: npm run test", "api: go test ./..."},
+		},
+		{
+			name:     "filtered by name glob",
+			key:      "test",
+			filter:   TargetFilter{Name: "web-*"},
+			expected: []string{"web-app: npm run test"},
+		},
+		{
+			name:     "filtered by type",
+			key:      "test",
+			filter:   TargetFilter{Type: "go"},
+			expected: []string{"api: go test ./..."},
+		},
+		{
+			name:     "filtered by secondary type",
+			key:      "test",
+			filter:   TargetFilter{Type: "make"},
+			expected: []string{"api: go test ./..."},
+		},
+		{
+			name:        "no location has key",
+			key:         "deploy",
+			expectError: true,
+		},
+		{
+			name:        "invalid filter",
+			key:         "test",
+			filter:      TargetFilter{Name: "[web"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := FindTargets(cfg, tt.key, tt.filter)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("FindTargets() error = %v, expectError %v", err, tt.expectError)
+			}
+
+			var got []string
+			for _, target := range targets {
+				got = append(got, target.DisplayName+": "+target.Command)
+				if target.Directory != cfg.Dir {
+					t.Errorf("Target directory = %q, expected %q", target.Directory, cfg.Dir)
+				}
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("FindTargets() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunParallel(t *testing.T) {
+	dir := t.TempDir()
+	targets := []*SelectionResult{
+		{DisplayName: "one", Directory: dir, Command: "echo first; echo second"},
+		{DisplayName: "three", Directory: dir, Command: "printf partial; exit 2"},
+		{DisplayName: "two", Directory: dir, Command: "echo oops >&2"},
+	}
+
+	var stdout, stderr bytes.Buffer
+	results := RunParallel(context.Background(), targets, ParallelOptions{
+		Concurrency: 2,
+		Shell:       "sh",
+		Stdout:      &stdout,
+		Stderr:      &stderr,
+	})
+
+	if len(results) != len(targets) {
+		t.Fatalf("Expected %d results, got %d", len(targets), len(results))
+	}
+	if results[0].Failed() || results[2].Failed() {
+		t.Errorf("Expected first and last targets to pass: %+v", results)
+	}
+	if !results[1].Failed() || results[1].ExitCode != 2 {
+		t.Errorf("Expected second target to fail with exit code 2, got %+v", results[1])
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	sort.Strings(lines)
+	expected := []string{"one   | first", "one   | second", "three | partial"}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("stdout = %q, expected %q", lines, expected)
+	}
+	if strings.TrimSpace(stderr.String()) != "two   | oops" {
+		t.Errorf("stderr = %q, expected prefixed line", stderr.String())
+	}
+}
+
+func TestRunParallelFailFast(t *testing.T) {
+	dir := t.TempDir()
+	targets := []*SelectionResult{
+		{DisplayName: "fails", Directory: dir, Command: "exit 1"},
+		{DisplayName: "never", Directory: dir, Command: "echo should not run"},
+	}
+
+	var stdout bytes.Buffer
+	results := RunParallel(context.Background(), targets, ParallelOptions{
+		Concurrency: 1,
+		FailFast:    true,
+		Shell:       "sh",
+		Stdout:      &stdout,
+		Stderr:      &stdout,
+	})
+
+	if !results[0].Failed() {
+		t.Errorf("Expected first target to fail, got %+v", results[0])
+	}
+	if !results[1].Skipped {
+		t.Errorf("Expected second target to be skipped, got %+v", results[1])
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("Expected no output from skipped target, got %q", stdout.String())
+	}
+}
+
+func TestRunParallelFailFastCancelsRunning(t *testing.T) {
+	dir := t.TempDir()
+	targets := []*SelectionResult{
+		{DisplayName: "slow", Directory: dir, Command: "sleep 10"},
+		{DisplayName: "fails", Directory: dir, Command: "exit 1"},
+	}
+
+	start := time.Now()
+	results := RunParallel(context.Background(), targets, ParallelOptions{
+		Concurrency: 2,
+		FailFast:    true,
+		Shell:       "sh",
+		Stdout:      &bytes.Buffer{},
+		Stderr:      &bytes.Buffer{},
+	})
+
+	if time.Since(start) > cancelGracePeriod {
+		t.Errorf("Expected running commands to be canceled, took %s", time.Since(start))
+	}
+	if !results[0].Failed() || !results[1].Failed() {
+		t.Errorf("Expected both targets to fail: %+v", results)
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	results := []TaskResult{
+		{Target: &SelectionResult{DisplayName: "frontend"}, Duration: 1500 * time.Millisecond},
+		{Target: &SelectionResult{DisplayName: "backend"}, ExitCode: 3, Duration: 20 * time.Millisecond},
+		{Target: &SelectionResult{DisplayName: "docs"}, Skipped: true},
+	}
+
+	var buf bytes.Buffer
+	WriteSummary(&buf, results)
+	output := buf.String()
+
+	for _, expected := range []string{
+		"PASS    frontend  1.5s",
+		"FAIL    backend   20ms      3",
+		"SKIP    docs",
+		"1 passed, 1 failed, 1 skipped",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Summary missing %q:\n%s", expected, output)
+		}
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &prefixWriter{w: &buf, mu: &sync.Mutex{}, prefix: "[x] "}
+
+	w.Write([]byte("hel"))
+	w.Write([]byte("lo\nwor"))
+	w.Write([]byte("ld\r\n"))
+	w.Write([]byte("tail"))
+	w.Flush()
+
+	expected := "[x] hello\n[x] world\n[x] tail\n"
+	if buf.String() != expected {
+		t.Errorf("prefixWriter output = %q, expected %q", buf.String(), expected)
+	}
+}
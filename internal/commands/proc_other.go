@@ -0,0 +1,18 @@
+//go:build !unix
+
+package commands
+
+import (
+	"os/exec"
+	"time"
+)
+
+// setProcessGroup kills the process when cmd is canceled. Process groups
+// are not available on this platform, so there is no grace period and the
+// returned function does nothing.
+func setProcessGroup(cmd *exec.Cmd, grace time.Duration) func() {
+	cmd.Cancel = func() error {
+		return cmd.Process.Kill()
+	}
+	return func() {}
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package commands
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup runs cmd in its own process group and makes canceling it
+// interrupt the whole group, so commands started by the shell stop as well.
+// What is left of the group after the grace period is killed.
+//
+// Call the returned function once cmd.Wait returns: when the whole group has
+// exited by then, the kill is called off so that it cannot hit another group
+// reusing the id. A group id is not reused while the group has members.
+func setProcessGroup(cmd *exec.Cmd, grace time.Duration) func() {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	// Set by Cancel, which Wait waits for
+	var kill *time.Timer
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		kill = time.AfterFunc(grace, func() {
+			syscall.Kill(-pgid, syscall.SIGKILL)
+		})
+		return syscall.Kill(-pgid, syscall.SIGINT)
+	}
+	return func() {
+		if kill != nil && syscall.Kill(-cmd.Process.Pid, 0) == syscall.ESRCH {
+			kill.Stop()
+		}
+	}
+}
@@ -0,0 +1,54 @@
+//go:build unix
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestSetProcessGroupKillsGroupAfterGracePeriod(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+	defer r.Close()
+
+	// Background commands of a non-interactive shell ignore SIGINT, so only
+	// the kill after the grace period stops sleep
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", "sleep 30 & echo started; wait")
+	cmd.Stdout = w
+	stopKill := setProcessGroup(cmd, 100*time.Millisecond)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	w.Close()
+
+	reader := bufio.NewReader(r)
+	if line, err := reader.ReadString('\n'); err != nil || line != "started\n" {
+		t.Fatalf("ReadString() = %q, %v", line, err)
+	}
+	cancel()
+	cmd.Wait()
+	// sleep is still running, so the kill must stay scheduled
+	stopKill()
+
+	// sleep holds the write end of the pipe until it dies
+	closed := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, reader)
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the background command to be killed with its group")
+	}
+}
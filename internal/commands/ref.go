@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/martin/go-pm/internal/config"
+)
+
+// A reference addresses a command of a location as "location:command", the
+// format printed by gopm list and accepted by every subcommand.
+//
+// The location part ends at the first colon that is not escaped. Within it,
+// `\:` stands for a colon, `\]` for a closing bracket and `\\` for a backslash;
+// any other backslash is kept as is. The command part is taken verbatim, so it
+// may contain colons (e.g. "api:npm:lint" runs npm:lint in api). The location is
+// an id, a name or a path, see FindLocation.
+type Ref struct {
+	Location string
+	Command  string
+}
+
+// ParseRef parses a "location:command" reference
+func ParseRef(ref string) (Ref, error) {
+	location, rest, ok := splitEscaped(ref, ':')
+	if !ok {
+		return Ref{}, fmt.Errorf("invalid reference %q: expected location:command", ref)
+	}
+
+	parsed := Ref{Location: location, Command: strings.TrimSpace(rest)}
+	if parsed.Location == "" || parsed.Command == "" {
+		return Ref{}, fmt.Errorf("invalid reference %q: expected location:command", ref)
+	}
+	return parsed, nil
+}
+
+// String formats the reference so that ParseRef returns it unchanged
+func (r Ref) String() string {
+	return EscapeLocation(r.Location) + ":" + r.Command
+}
+
+// EscapeLocation escapes the characters of a location that would end the location
+// part of a reference
+func EscapeLocation(location string) string {
+	if !strings.ContainsAny(location, `\:]`) {
+		return location
+	}
+	var b strings.Builder
+	for _, r := range location {
+		if r == '\\' || r == ':' || r == ']' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// splitEscaped unescapes s up to the first unescaped sep and returns it together
+// with the rest of s after sep. ok is false when s has no unescaped sep.
+func splitEscaped(s string, sep byte) (head, rest string, ok bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && (s[i+1] == '\\' || s[i+1] == ':' || s[i+1] == ']'):
+			b.WriteByte(s[i+1])
+			i++
+		case s[i] == sep:
+			return b.String(), s[i+1:], true
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), "", false
+}
+
+// LocationRef returns the location part of references to a location: its
+// display name, or its id when the name also addresses another location, by
+// being its name too or its id (ids are looked up first, see FindLocation)
+func LocationRef(cfg *config.Config, location *config.Location) string {
+	name := location.DisplayName()
+	if location.ID == "" {
+		return name
+	}
+	for i := range cfg.Locations {
+		other := &cfg.Locations[i]
+		if other.ID != location.ID && (other.DisplayName() == name || other.ID == name) {
+			return location.ID
+		}
+	}
+	return name
+}
+
+// GetRefExecutionDetails is GetExecutionDetails for a "location:command" reference
+func GetRefExecutionDetails(cfg *config.Config, ref string) (*SelectionResult, error) {
+	parsed, err := ParseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	return GetExecutionDetails(cfg, parsed.Location, parsed.Command)
+}
+
+// ambiguousLocationError lists the ids of the locations sharing a name
+func ambiguousLocationError(name string, locations []*config.Location) error {
+	ids := make([]string, 0, len(locations))
+	for _, location := range locations {
+		ids = append(ids, location.ID)
+	}
+	sort.Strings(ids)
+	return fmt.Errorf("location %q is ambiguous, use one of the ids: %s", name, strings.Join(ids, ", "))
+}
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/martin/go-pm/internal/config"
+)
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		ref      string
+		location string
+		command  string
+		wantErr  bool
+	}{
+		{ref: "api:build", location: "api", command: "build"},
+		{ref: "api: go run ./cmd/api", location: "api", command: "go run ./cmd/api"},
+		{ref: "mixed:npm:lint", location: "mixed", command: "npm:lint"},
+		{ref: `tools\:legacy:make`, location: "tools:legacy", command: "make"},
+		{ref: `c\\dir\:x:ls`, location: `c\dir:x`, command: "ls"},
+		{ref: `a\b:ls`, location: `a\b`, command: "ls"},
+		{ref: `api:echo a\:b`, location: "api", command: `echo a\:b`},
+		{ref: "api", wantErr: true},
+		{ref: `api\:build`, wantErr: true},
+		{ref: ":build", wantErr: true},
+		{ref: "api:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			ref, err := ParseRef(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if ref.Location != tt.location || ref.Command != tt.command {
+				t.Errorf("ParseRef() = %+v, expected location %q and command %q", ref, tt.location, tt.command)
+			}
+		})
+	}
+}
+
+func TestRefStringRoundTrip(t *testing.T) {
+	for _, location := range []string{"api", "tools:legacy", `c:\work`, "odd]name", "/abs/path"} {
+		ref := Ref{Location: location, Command: "npm:lint"}
+		parsed, err := ParseRef(ref.String())
+		if err != nil {
+			t.Fatalf("ParseRef(%q) error = %v", ref.String(), err)
+		}
+		if parsed != ref {
+			t.Errorf("ParseRef(%q) = %+v, expected %+v", ref.String(), parsed, ref)
+		}
+	}
+}
+
+func TestFindLocationByIDAndDuplicateName(t *testing.T) {
+	cfg := &config.Config{
+		Dir: "/repo",
+		Locations: []config.Location{
+			{Name: "report", ID: "jobs/daily/report", Location: "/repo/jobs/daily/report"},
+			{Name: "report", ID: "jobs/weekly/report", Location: "/repo/jobs/weekly/report"},
+			{Name: "api", ID: "services/api", Location: "/repo/services/api"},
+		},
+	}
+
+	location, err := FindLocation(cfg, "jobs/weekly/report")
+	if err != nil || location.Location != "/repo/jobs/weekly/report" {
+		t.Errorf("FindLocation(id) = %+v, %v", location, err)
+	}
+
+	_, err = FindLocation(cfg, "report")
+	if err == nil || !strings.Contains(err.Error(), "jobs/daily/report, jobs/weekly/report") {
+		t.Errorf("Expected an ambiguous name error listing the ids, got %v", err)
+	}
+
+	if ref := LocationRef(cfg, &cfg.Locations[0]); ref != "jobs/daily/report" {
+		t.Errorf("LocationRef() = %q, expected the id", ref)
+	}
+	if ref := LocationRef(cfg, &cfg.Locations[2]); ref != "api" {
+		t.Errorf("LocationRef() = %q, expected the name", ref)
+	}
+}
+
+func TestLocationRefNameMatchingAnotherID(t *testing.T) {
+	cfg := &config.Config{
+		Dir: "/repo",
+		Locations: []config.Location{
+			{Name: "frontend", ID: "web", Location: "/repo/web"},
+			{Name: "ui", ID: "frontend", Location: "/repo/frontend"},
+		},
+	}
+
+	// "frontend" is the id of the second location, so the first one is referenced by id
+	ref := LocationRef(cfg, &cfg.Locations[0])
+	if ref != "web" {
+		t.Errorf("LocationRef() = %q, expected the id", ref)
+	}
+	location, err := FindLocation(cfg, ref)
+	if err != nil || location.Location != "/repo/web" {
+		t.Errorf("FindLocation(%q) = %+v, %v", ref, location, err)
+	}
+	if ref := LocationRef(cfg, &cfg.Locations[1]); ref != "ui" {
+		t.Errorf("LocationRef() = %q, expected the name", ref)
+	}
+}
+
+func TestListCommandsRoundTrip(t *testing.T) {
+	cfg := &config.Config{
+		Dir: "/repo",
+		Locations: []config.Location{
+			{Name: "report", ID: "jobs/daily/report", Location: "/repo/jobs/daily/report", Commands: []config.Command{{Run: "./run.sh"}}},
+			{Name: "report", ID: "jobs/weekly/report", Location: "/repo/jobs/weekly/report", Commands: []config.Command{{Run: "./run.sh"}}},
+			{Name: "frontend", ID: "web", Location: "/repo/web", Commands: []config.Command{
+				{Name: "hello", Run: "echo hi", Description: "Says hi"},
+				{Run: "npm run lint", Key: "lint", Parser: "package_json_scripts", Type: "npm"},
+			}},
+			{Name: "ui", ID: "frontend", Location: "/repo/frontend", Commands: []config.Command{{Run: "echo UI"}}},
+			{ID: "tools:legacy", Location: "/repo/tools:legacy", Commands: []config.Command{{Run: "make a:b"}}},
+		},
+	}
+
+	lines := ListCommands(cfg)
+	i := 0
+	for l := range cfg.Locations {
+		location := &cfg.Locations[l]
+		for _, command := range location.Commands {
+			if i >= len(lines) {
+				t.Fatalf("ListCommands() = %q, missing %s", lines, command.Run)
+			}
+			line := lines[i]
+			i++
+
+			ref, err := ParseRef(line)
+			if err != nil {
+				t.Errorf("ParseRef(%q) error = %v", line, err)
+				continue
+			}
+			found, err := FindLocation(cfg, ref.Location)
+			if err != nil || found != location {
+				t.Errorf("FindLocation(%q) = %+v, %v, expected %s", ref.Location, found, err, location.ID)
+				continue
+			}
+			if got, err := FindCommand(found, ref.Command); err != nil || got.Run != command.Run {
+				t.Errorf("FindCommand(%q) = %+v, %v, expected %q", ref.Command, got, err, command.Run)
+			}
+		}
+	}
+	if i != len(lines) {
+		t.Errorf("ListCommands() = %q, expected %d lines", lines, i)
+	}
+}
+
+func TestGetRefExecutionDetails(t *testing.T) {
+	cfg := testGetConfig()
+
+	result, err := GetRefExecutionDetails(cfg, "mixed:make:build")
+	if err != nil {
+		t.Fatalf("GetRefExecutionDetails() error = %v", err)
+	}
+	if result.Command != "make build" || result.Directory != "/repo/mixed" {
+		t.Errorf("GetRefExecutionDetails() = %+v", result)
+	}
+
+	if _, err := GetRefExecutionDetails(cfg, "frontend"); err == nil {
+		t.Error("Expected an error for a reference without a command")
+	}
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/martin/go-pm/internal/config"
+)
+
+// DefaultShell is used when neither the config nor $SHELL specify a shell
+const DefaultShell = "/bin/sh"
+
+// ExecOptions controls how a selected command is executed
+type ExecOptions struct {
+	Shell  string    // Shell used to run the command (falls back to $SHELL, then DefaultShell)
+	Stdin  io.Reader // Defaults to os.Stdin
+	Stdout io.Writer // Defaults to os.Stdout
+	Stderr io.Writer // Defaults to os.Stderr
+}
+
+// ResolveShell returns the shell to use for running commands.
+// The configured shell wins over $SHELL, which wins over DefaultShell.
+func ResolveShell(cfg *config.Config) string {
+	if cfg != nil && cfg.Shell != "" {
+		return cfg.Shell
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return DefaultShell
+}
+
+// ResolveDirectory returns the absolute directory a selection should run in.
+// Relative directories are resolved against the directory of the config file.
+func ResolveDirectory(cfg *config.Config, directory string) (string, error) {
+	absDir := AbsDirectory(cfg, directory)
+
+	info, err := os.Stat(absDir)
+	if err != nil {
+		return "", fmt.Errorf("directory %q does not exist", absDir)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%q is not a directory", absDir)
+	}
+
+	return absDir, nil
+}
+
+// Execute runs the selected command in its directory and returns the child's exit code.
+// Standard streams are forwarded to the child, as are termination signals received by gopm.
+func Execute(result *SelectionResult, opts ExecOptions) (int, error) {
+	if result == nil || result.Command == "" {
+		return 1, fmt.Errorf("no command to execute")
+	}
+
+	shell := opts.Shell
+	if shell == "" {
+		shell = ResolveShell(nil)
+	}
+	shell = commandShell(result, shell)
+
+	cmd := exec.Command(shell, "-c", result.Command)
+	cmd.Dir = result.Directory
+	cmd.Env = commandEnv(result)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	if cmd.Stdin == nil {
+		cmd.Stdin = os.Stdin
+	}
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+
+	if err := cmd.Start(); err != nil {
+		return 1, fmt.Errorf("failed to start %s: %w", shell, err)
+	}
+
+	// The child shares our process group, so Ctrl+C from the terminal already
+	// reaches it. We only need to keep gopm alive until the child exits and
+	// relay signals that were sent to gopm directly.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	defer signal.Stop(signals)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig != os.Interrupt {
+					cmd.Process.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return exitCode(cmd.Wait())
+}
+
+// commandShell returns the shell configured for the command, or the given default
+func commandShell(result *SelectionResult, shell string) string {
+	if result.Shell != "" {
+		return result.Shell
+	}
+	return shell
+}
+
+// commandEnv returns the environment of gopm with the variables of the command added
+func commandEnv(result *SelectionResult) []string {
+	env := os.Environ()
+
+	keys := make([]string, 0, len(result.Env))
+	for key := range result.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		env = append(env, key+"="+result.Env[key])
+	}
+	return env
+}
+
+// exitCode converts the result of waiting on a child into a shell-style exit code
+func exitCode(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 1, err
+	}
+
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal()), nil
+	}
+
+	return exitErr.ExitCode(), nil
+}
@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/martin/go-pm/internal/config"
+)
+
+func TestResolveShell(t *testing.T) {
+	t.Setenv("SHELL", "/usr/bin/zsh")
+
+	if shell := ResolveShell(&config.Config{Shell: "/bin/bash"}); shell != "/bin/bash" {
+		t.Errorf("ResolveShell() with configured shell = %q, expected %q", shell, "/bin/bash")
+	}
+	if shell := ResolveShell(&config.Config{}); shell != "/usr/bin/zsh" {
+		t.Errorf("ResolveShell() with $SHELL = %q, expected %q", shell, "/usr/bin/zsh")
+	}
+
+	t.Setenv("SHELL", "")
+	if shell := ResolveShell(nil); shell != DefaultShell {
+		t.Errorf("ResolveShell() without config or $SHELL = %q, expected %q", shell, DefaultShell)
+	}
+}
+
+func TestResolveDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "packages", "frontend"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("test"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	cfg := &config.Config{Dir: tmpDir}
+
+	tests := []struct {
+		name      string
+		directory string
+		expected  string
+		wantErr   bool
+	}{
+		{
+			name:      "relative to config directory",
+			directory: "packages/frontend",
+			expected:  filepath.Join(tmpDir, "packages", "frontend"),
+		},
+		{
+			name:      "absolute directory",
+			directory: filepath.Join(tmpDir, "packages"),
+			expected:  filepath.Join(tmpDir, "packages"),
+		},
+		{
+			name:      "missing directory",
+			directory: "packages/missing",
+			wantErr:   true,
+		},
+		{
+			name:      "file instead of directory",
+			directory: "file.txt",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ResolveDirectory(cfg, tt.directory)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveDirectory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && dir != tt.expected {
+				t.Errorf("ResolveDirectory() = %q, expected %q", dir, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name         string
+		command      string
+		env          map[string]string
+		stdin        string
+		expectedCode int
+		expectedOut  string
+		expectedErr  string
+	}{
+		{
+			name:         "runs in the selected directory",
+			command:      "pwd",
+			expectedCode: 0,
+			expectedOut:  tmpDir,
+		},
+		{
+			name:         "forwards stdin",
+			command:      "cat",
+			stdin:        "hello from stdin",
+			expectedCode: 0,
+			expectedOut:  "hello from stdin",
+		},
+		{
+			name:         "forwards stderr",
+			command:      "echo oops >&2",
+			expectedCode: 0,
+			expectedErr:  "oops",
+		},
+		{
+			name:         "returns child exit code",
+			command:      "exit 3",
+			expectedCode: 3,
+		},
+		{
+			name:         "handles quotes in commands",
+			command:      `echo "it's \"quoted\""`,
+			expectedCode: 0,
+			expectedOut:  `it's "quoted"`,
+		},
+		{
+			name:         "adds command environment",
+			command:      `echo "$GREETING $PATH" | cut -d: -f1`,
+			env:          map[string]string{"GREETING": "hello"},
+			expectedCode: 0,
+			expectedOut:  "hello " + strings.Split(os.Getenv("PATH"), ":")[0],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code, err := Execute(&SelectionResult{Directory: tmpDir, Command: tt.command, Env: tt.env}, ExecOptions{
+				Shell:  "sh",
+				Stdin:  strings.NewReader(tt.stdin),
+				Stdout: &stdout,
+				Stderr: &stderr,
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if code != tt.expectedCode {
+				t.Errorf("Execute() exit code = %d, expected %d", code, tt.expectedCode)
+			}
+			if got := strings.TrimSpace(stdout.String()); got != tt.expectedOut {
+				t.Errorf("Execute() stdout = %q, expected %q", got, tt.expectedOut)
+			}
+			if got := strings.TrimSpace(stderr.String()); got != tt.expectedErr {
+				t.Errorf("Execute() stderr = %q, expected %q", got, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	if _, err := Execute(nil, ExecOptions{}); err == nil {
+		t.Error("Expected error for nil selection")
+	}
+
+	if _, err := Execute(&SelectionResult{Directory: t.TempDir()}, ExecOptions{}); err == nil {
+		t.Error("Expected error for empty command")
+	}
+
+	code, err := Execute(&SelectionResult{Directory: t.TempDir(), Command: "true"}, ExecOptions{Shell: "/nonexistent/shell"})
+	if err == nil {
+		t.Error("Expected error for missing shell")
+	}
+	if code == 0 {
+		t.Error("Expected non-zero exit code for missing shell")
+	}
+
+	// The shell of a command wins over the configured one
+	result := &SelectionResult{Directory: t.TempDir(), Command: "true", Shell: "/nonexistent/shell"}
+	if _, err := Execute(result, ExecOptions{Shell: "sh"}); err == nil {
+		t.Error("Expected error for missing command shell")
+	}
+}
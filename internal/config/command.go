@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/martin/go-pm/internal/parsers"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceConfig is the parser reported for commands declared in the config file
+const SourceConfig = "config"
+
+// Command is a command of a location. In the config file it is either a plain
+// string, which is the command line to run, or a mapping with the fields below.
+type Command struct {
+	Name        string            `yaml:"name,omitempty"`        // Text shown when selecting the command, defaults to Run
+	Run         string            `yaml:"run"`                   // Command line passed to the shell
+	Description string            `yaml:"description,omitempty"` // Shown in the preview
+	Cwd         string            `yaml:"cwd,omitempty"`         // Subdirectory of the location to run in
+	Env         map[string]string `yaml:"env,omitempty"`         // Added to the environment of the command
+	Shell       string            `yaml:"shell,omitempty"`       // Overrides the shell of the config
+	Tags        []string          `yaml:"tags,omitempty"`        // Free-form labels, shown in the preview
+
+	// Commands generated from a project type record how they were produced.
+	// They are empty for commands declared in the config file.
+	Key    string `yaml:"-"` // Key reported by the parser, namespaced as "type:key" when a location has several types
+	Parser string `yaml:"-"` // Parser that produced the command (e.g. "package_json_scripts")
+	Type   string `yaml:"-"` // Project type that produced the command
+
+	// Origin is the config file that declared the command, empty for generated commands
+	Origin string `yaml:"-"`
+}
+
+// UnmarshalYAML decodes a command from either a string or a mapping
+func (c *Command) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var run string
+		if err := value.Decode(&run); err != nil {
+			return err
+		}
+		*c = Command{Run: run}
+		return nil
+	}
+
+	type plain Command
+	var raw plain
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	if raw.Run == "" {
+		return fmt.Errorf("line %d: command %q has no run field", value.Line, raw.Name)
+	}
+	if err := parsers.ValidateCwd(raw.Cwd); err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+
+	*c = Command(raw)
+	return nil
+}
+
+// Title returns the text shown for the command
+func (c Command) Title() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Run
+}
+
+// Source returns the parser that produced the command, or SourceConfig
+func (c Command) Source() string {
+	if c.Parser != "" {
+		return c.Parser
+	}
+	return SourceConfig
+}
+
+// Matches reports whether ref names the command by its name, command line or parser key
+func (c Command) Matches(ref string) bool {
+	return ref != "" && (ref == c.Run || ref == c.Name || ref == c.Key)
+}
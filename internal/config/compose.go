@@ -0,0 +1,295 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfigFile reads a config file and composes it with the files it extends and
+// includes. Relative paths are anchored at the directory of the file declaring them.
+// stack lists the files being loaded, to detect cycles.
+//
+// The layers are merged in order: extended base configs, included files and then
+// the file itself, each layer overriding the ones before, see mergeConfigs. Name,
+// root and merge_parents are taken from the file itself only.
+func loadConfigFile(path string, stack []string) (*Config, error) {
+	for i, loading := range stack {
+		if loading == path {
+			cycle := append(append([]string(nil), stack[i:]...), path)
+			return nil, fmt.Errorf("config files form a cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	stack = append(stack, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	config.Dir = dir
+	anchorConfig(&config, path)
+
+	var layers []*Config
+	for _, base := range config.Extends {
+		basePath := expandPath(base, dir)
+		if _, err := os.Stat(basePath); err != nil {
+			return nil, fmt.Errorf("%s extends %s: %w", path, base, err)
+		}
+
+		baseConfig, err := loadConfigFile(basePath, stack)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, baseConfig)
+	}
+
+	includes, err := resolveIncludes(config.Include, dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, include := range includes {
+		included, err := loadConfigFile(include, stack)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, included)
+	}
+
+	composed := &config
+	if len(layers) > 0 {
+		composed = &Config{}
+		for _, layer := range append(layers, &config) {
+			composed = mergeConfigs(composed, layer)
+		}
+		composed.Dir = dir
+		// How the file nests in its parents is its own, never a layer's
+		composed.Name = config.Name
+		composed.Root = config.Root
+		composed.MergeParents = config.MergeParents
+	}
+	composed.Extends = nil
+	composed.Include = nil
+	return composed, nil
+}
+
+// anchorConfig makes the paths of a config file absolute, relative to its directory,
+// and records the file as the source of its locations
+func anchorConfig(config *Config, path string) {
+	dir := filepath.Dir(path)
+
+	// Anchor locations at the config file, never at the caller's working directory
+	for i := range config.Locations {
+		location := &config.Locations[i]
+		location.Source = path
+		for j := range location.Commands {
+			location.Commands[j].Origin = path
+		}
+		location.Path = location.Location
+		location.Location = expandPath(location.Location, dir)
+		for j, exclude := range location.Exclude {
+			location.Exclude[j] = expandPath(exclude, dir)
+		}
+	}
+
+	if config.Discover != nil {
+		config.Discover.Root = expandPath(config.Discover.Root, dir)
+		for i, ignore := range config.Discover.Ignore {
+			config.Discover.Ignore[i] = expandPath(ignore, dir)
+		}
+	}
+}
+
+// resolveIncludes returns the files named by include paths and glob patterns,
+// relative to dir. A path without wildcards must exist, a pattern may match nothing.
+func resolveIncludes(includes []string, dir string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, include := range includes {
+		pattern := expandPath(include, dir)
+		if !hasGlobMeta(pattern) {
+			if _, err := os.Stat(pattern); err != nil {
+				return nil, fmt.Errorf("include %s: %w", include, err)
+			}
+			if !seen[pattern] {
+				seen[pattern] = true
+				files = append(files, pattern)
+			}
+			continue
+		}
+
+		if err := validateGlobPattern(pattern); err != nil {
+			return nil, fmt.Errorf("include %s: %w", include, err)
+		}
+		alternatives, err := expandBraces(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		// Wildcards in directories are expanded like location globs, the file name with filepath.Glob
+		var matches []string
+		for _, alternative := range alternatives {
+			dirs := []string{filepath.Dir(alternative)}
+			if hasGlobMeta(dirs[0]) {
+				dirs, err = globDirs(dirs[0], dir)
+				if err != nil {
+					return nil, err
+				}
+			}
+			for _, matchDir := range dirs {
+				found, err := filepath.Glob(filepath.Join(matchDir, filepath.Base(alternative)))
+				if err != nil {
+					return nil, fmt.Errorf("include %s: %w", include, err)
+				}
+				matches = append(matches, found...)
+			}
+		}
+		sort.Strings(matches)
+
+		for _, match := range matches {
+			if !seen[match] && !isDirectory(match) {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+	return files, nil
+}
+
+// mergeConfigs returns base overridden by override:
+//   - settings (shell, sort, workspaces, discover, only, hide) are replaced when override sets them
+//   - locations are appended, except that a location with the same id or name (or
+//     the same directory when unnamed) as a base location is merged into it, see mergeLocation
+func mergeConfigs(base, override *Config) *Config {
+	merged := *base
+	if override.Shell != "" {
+		merged.Shell = override.Shell
+	}
+	if override.Sort != "" {
+		merged.Sort = override.Sort
+	}
+	if len(override.Workspaces) > 0 {
+		merged.Workspaces = override.Workspaces
+	}
+	if override.Discover != nil {
+		merged.Discover = override.Discover
+	}
+	if len(override.Only) > 0 {
+		merged.Only = override.Only
+	}
+	if len(override.Hide) > 0 {
+		merged.Hide = override.Hide
+	}
+
+	merged.Locations = append([]Location(nil), base.Locations...)
+	for _, location := range override.Locations {
+		if i := findLocation(base.Locations, location); i >= 0 {
+			merged.Locations[i] = mergeLocation(merged.Locations[i], location)
+			continue
+		}
+		merged.Locations = append(merged.Locations, location)
+	}
+	return &merged
+}
+
+// findLocation returns the index of the location with the same identity, or -1.
+// Locations of different nested projects never share an identity.
+func findLocation(locations []Location, location Location) int {
+	for i := range locations {
+		if locations[i].Project != location.Project {
+			continue
+		}
+		if location.ID != "" && locations[i].ID == location.ID {
+			return i
+		}
+		if location.Name != "" && locations[i].Name == location.Name {
+			return i
+		}
+		if location.Name == "" && locations[i].Name == "" && locations[i].Location == location.Location {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeLocation overrides a location with another declaration of it. Fields set
+// in override replace those of base, exclude lists are appended and commands are
+// merged with mergeCommandLists. The location keeps the source of base unless
+// override moves it to another directory.
+func mergeLocation(base, override Location) Location {
+	merged := base
+	if override.Path != "" {
+		merged.Location = override.Location
+		merged.Path = override.Path
+	}
+	if override.ID != "" {
+		merged.ID = override.ID
+	}
+	if len(override.Types) > 0 {
+		merged.Type = override.Type
+		merged.Types = override.Types
+	}
+	if override.Sort != "" {
+		merged.Sort = override.Sort
+	}
+	merged.Exclude = append(append([]string(nil), base.Exclude...), override.Exclude...)
+	merged.Commands = mergeCommandLists(base.Commands, override.Commands)
+	// The location stays declared where its directory was set, so generated and
+	// inherited commands keep reporting that file as their origin
+	if override.Path != "" {
+		merged.Source = override.Source
+		merged.Line = override.Line
+		merged.Column = override.Column
+	}
+	return merged
+}
+
+// mergeCommandLists appends the override commands to the base commands. A command
+// with the same name (or run line when unnamed) replaces the base command in place,
+// and their env maps are merged key by key.
+func mergeCommandLists(base, override []Command) []Command {
+	merged := append([]Command(nil), base...)
+	for _, cmd := range override {
+		replaced := false
+		for i := range base {
+			if commandIdentity(base[i]) != commandIdentity(cmd) {
+				continue
+			}
+			env := make(map[string]string)
+			for key, value := range base[i].Env {
+				env[key] = value
+			}
+			for key, value := range cmd.Env {
+				env[key] = value
+			}
+			if len(env) == 0 {
+				env = nil
+			}
+			merged[i] = cmd
+			merged[i].Env = env
+			replaced = true
+			break
+		}
+		if !replaced {
+			merged = append(merged, cmd)
+		}
+	}
+	return merged
+}
+
+// commandIdentity is what makes two declared commands the same when merging
+func commandIdentity(cmd Command) string {
+	if cmd.Name != "" {
+		return "name:" + cmd.Name
+	}
+	return "run:" + cmd.Run
+}
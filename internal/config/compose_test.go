@@ -0,0 +1,228 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigExtends(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"presets/base.yaml": `shell: /bin/bash
+sort: alpha
+locations:
+  - name: api
+    location: ../services/api
+    type: none
+    commands:
+      - name: build
+        run: make build
+        env:
+          GOOS: linux
+          CGO_ENABLED: "0"
+      - make lint
+  - name: web
+    location: ../web
+    type: none
+    commands:
+      - npm start
+`,
+		"repo/.gopmrc": `extends: ../presets/base.yaml
+shell: /bin/zsh
+locations:
+  - name: api
+    commands:
+      - name: build
+        run: make build-all
+        env:
+          GOOS: darwin
+      - make test
+  - name: docs
+    location: docs
+    type: none
+    commands:
+      - mkdocs serve
+`,
+	})
+
+	config, err := LoadConfig(filepath.Join(tmpDir, "repo", ".gopmrc"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if config.Shell != "/bin/zsh" {
+		t.Errorf("Expected the shell of the extending config, got %q", config.Shell)
+	}
+	if config.Sort != SortAlpha {
+		t.Errorf("Expected the sort of the base config, got %q", config.Sort)
+	}
+
+	var names []string
+	for _, location := range config.Locations {
+		names = append(names, location.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"api", "web", "docs"}) {
+		t.Fatalf("Expected locations [api web docs], got %v", names)
+	}
+
+	api := config.Locations[0]
+	if api.Location != filepath.Join(tmpDir, "services", "api") {
+		t.Errorf("Expected api to stay anchored at the base config, got %s", api.Location)
+	}
+	repoConfig := filepath.Join(tmpDir, "repo", ".gopmrc")
+	baseConfig := filepath.Join(tmpDir, "presets", "base.yaml")
+	expectedCommands := []Command{
+		{Name: "build", Run: "make build-all", Env: map[string]string{"GOOS": "darwin", "CGO_ENABLED": "0"}, Origin: repoConfig},
+		{Run: "make lint", Origin: baseConfig},
+		{Run: "make test", Origin: repoConfig},
+	}
+	if !reflect.DeepEqual(api.Commands, expectedCommands) {
+		t.Errorf("Merged api commands = %+v, expected %+v", api.Commands, expectedCommands)
+	}
+
+	if docs := config.Locations[2]; docs.Location != filepath.Join(tmpDir, "repo", "docs") || docs.Path != "docs" {
+		t.Errorf("Expected docs anchored at the extending config, got %s (%s)", docs.Location, docs.Path)
+	}
+	// Locations outside the directory of the loaded config are shown by their full path
+	if web := config.Locations[1]; web.Path != filepath.Join(tmpDir, "web") || web.Source != baseConfig {
+		t.Errorf("Expected web from the base config, got path %s from %s", web.Path, web.Source)
+	}
+}
+
+func TestLoadConfigInclude(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".gopmrc": `include:
+  - "teams/*/gopm.yaml"
+  - shared.yaml
+locations:
+  - name: root
+    location: .
+    type: none
+    commands:
+      - echo root
+`,
+		"shared.yaml": `locations:
+  - name: tools
+    location: tools
+    type: none
+    commands:
+      - echo tools
+`,
+		"teams/payments/gopm.yaml": `locations:
+  - name: payments
+    location: service
+    type: none
+    commands:
+      - echo payments
+`,
+		"teams/search/gopm.yaml": `locations:
+  - name: search-team
+    location: "search-{indexer,api}"
+    type: none
+    commands:
+      - echo search
+`,
+		"teams/payments/service/README.md":      "",
+		"teams/search/search-indexer/README.md": "",
+		"teams/search/search-api/README.md":     "",
+	})
+
+	config, err := LoadConfig(filepath.Join(tmpDir, ".gopmrc"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	expected := []struct {
+		name, path, source string
+	}{
+		{"payments", "teams/payments/service", "teams/payments/gopm.yaml"},
+		{"search-team", "teams/search/search-api", "teams/search/gopm.yaml"},
+		{"search-team", "teams/search/search-indexer", "teams/search/gopm.yaml"},
+		{"tools", "tools", "shared.yaml"},
+		{"root", ".", ".gopmrc"},
+	}
+	if len(config.Locations) != len(expected) {
+		t.Fatalf("Expected %d locations, got %d", len(expected), len(config.Locations))
+	}
+	for i, location := range config.Locations {
+		if location.Name != expected[i].name || location.Path != expected[i].path {
+			t.Errorf("Location[%d] = %s at %s, expected %s at %s", i, location.Name, location.Path, expected[i].name, expected[i].path)
+		}
+		if location.Source != filepath.Join(tmpDir, expected[i].source) {
+			t.Errorf("Location[%d].Source = %s, expected %s", i, location.Source, expected[i].source)
+		}
+	}
+}
+
+func TestLoadConfigCompositionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr []string
+	}{
+		{
+			name: "extends cycle",
+			files: map[string]string{
+				".gopmrc": "extends: a.yaml\n",
+				"a.yaml":  "include: [b.yaml]\n",
+				"b.yaml":  "extends: a.yaml\n",
+			},
+			wantErr: []string{"cycle", "a.yaml -> ", "b.yaml -> ", "a.yaml"},
+		},
+		{
+			name: "missing include",
+			files: map[string]string{
+				".gopmrc": "include: [missing.yaml]\n",
+			},
+			wantErr: []string{"include missing.yaml"},
+		},
+		{
+			name: "missing base",
+			files: map[string]string{
+				".gopmrc": "extends: base.yaml\n",
+			},
+			wantErr: []string{"extends base.yaml"},
+		},
+		{
+			name: "errors name the file of the location",
+			files: map[string]string{
+				".gopmrc": "include: [team.yaml]\n",
+				"team.yaml": `locations:
+  - name: broken
+    location: .
+    type: none
+    sort: newest
+`,
+			},
+			wantErr: []string{"broken (from ", "team.yaml)", `invalid sort "newest"`},
+		},
+		{
+			name: "parse errors name the file",
+			files: map[string]string{
+				".gopmrc":   "include: [team.yaml]\n",
+				"team.yaml": "locations: {\n",
+			},
+			wantErr: []string{"failed to parse config file", "team.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeFiles(t, tmpDir, tt.files)
+
+			_, err := LoadConfig(filepath.Join(tmpDir, ".gopmrc"))
+			if err == nil {
+				t.Fatal("LoadConfig() expected an error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("LoadConfig() error = %v, expected it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -1,114 +1,589 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 
+	"github.com/martin/go-pm/internal/parsers"
 	"github.com/martin/go-pm/internal/projecttypes"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	Locations []Location `yaml:"locations"`
+
+	// Extends names base configs this config inherits from, see mergeConfigs.
+	// It may be written as a single string.
+	Extends []string `yaml:"-"`
+
+	// Include lists config files, or glob patterns of them, whose locations are merged in
+	Include []string `yaml:"include,omitempty"`
+
+	// Workspaces imports locations from native workspace manifests, e.g. go.work.
+	// It is "auto" or a list of workspace kinds, and may be written as a single string.
+	Workspaces []string `yaml:"-"`
+
+	// Discover adds a location for every project directory found below a root
+	Discover *Discover `yaml:"discover,omitempty"`
+
+	// Shell overrides $SHELL for running commands
+	Shell string `yaml:"shell,omitempty"`
+
+	// Sort orders the commands of every location, see SortConfig
+	Sort string `yaml:"sort,omitempty"`
+
+	// Only keeps the locations matching one of these patterns, see Location.MatchesPattern
+	Only []string `yaml:"only,omitempty"`
+
+	// Hide drops the locations matching one of these patterns
+	Hide []string `yaml:"hide,omitempty"`
+
+	// Dir is the directory containing the loaded config file
+	Dir string `yaml:"-"`
+
+	// LocalPath is the personal overlay merged on top of the config, if any
+	LocalPath string `yaml:"-"`
+
+	// Name names the project of this config file. It prefixes the location names
+	// when the file is merged into a nested config, and defaults to the directory name.
+	Name string `yaml:"name,omitempty"`
+
+	// MergeParents merges the config files of parent directories, up to one with Root set
+	MergeParents bool `yaml:"merge_parents,omitempty"`
+
+	// Root stops the search for parent config files at this one
+	Root bool `yaml:"root,omitempty"`
+
+	// Warnings lists the problems found while loading that left out part of the
+	// config, e.g. the commands of a location whose parser failed
+	Warnings []Warning `yaml:"-"`
+}
+
+// UnmarshalYAML decodes a config, accepting either a string or a list for
+// workspaces and extends
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	type plain Config
+	var raw struct {
+		plain      `yaml:",inline"`
+		Workspaces stringList `yaml:"workspaces,omitempty"`
+		Extends    stringList `yaml:"extends,omitempty"`
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+
+	*c = Config(raw.plain)
+	c.Workspaces = raw.Workspaces
+	c.Extends = raw.Extends
+	return nil
 }
 
 type Location struct {
-	Name     string   `yaml:"name,omitempty"`
-	Location string   `yaml:"location"`
-	Type     string   `yaml:"type,omitempty"`
-	Commands []string `yaml:"commands,omitempty"`
+	Name string `yaml:"name,omitempty"`
+
+	// ID identifies the location uniquely. It defaults to the path of the
+	// location relative to the config directory, see assignLocationIDs.
+	ID string `yaml:"id,omitempty"`
+
+	// Location is the directory of the location. Once loaded it is absolute,
+	// anchored at the directory of the config file.
+	Location string `yaml:"location"`
+
+	// Exclude lists glob patterns, relative to the config file, of directories
+	// a glob location should skip
+	Exclude []string `yaml:"exclude,omitempty"`
+
+	// Path is the directory as written in the config file (or relative to the
+	// config file for glob matches), shown for locations without a name
+	Path string `yaml:"-"`
+
+	// Type is the primary project type. The type field in the config file
+	// accepts a single type or a list, see Types.
+	Type string `yaml:"-"`
+
+	// Types lists every project type of the location, Type is the first one
+	Types []string `yaml:"-"`
+
+	Commands []Command `yaml:"commands,omitempty"`
+
+	// Sort overrides the command order of the config for this location
+	Sort string `yaml:"sort,omitempty"`
+
+	// Source is the config file the location was declared in, and Line and
+	// Column the position of the declaration in it
+	Source string `yaml:"-"`
+	Line   int    `yaml:"-"`
+	Column int    `yaml:"-"`
+
+	// Pattern is the glob pattern, as written, a location was expanded from
+	Pattern string `yaml:"-"`
+
+	// Project is the name of the nested project the location came from, see Config.MergeParents
+	Project string `yaml:"-"`
+}
+
+// UnmarshalYAML decodes a location, accepting either a string or a list for type
+func (l *Location) UnmarshalYAML(value *yaml.Node) error {
+	type plain Location
+	var raw struct {
+		plain `yaml:",inline"`
+		Type  stringList `yaml:"type,omitempty"`
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+
+	*l = Location(raw.plain)
+	l.Line = value.Line
+	l.Column = value.Column
+	l.Types = raw.Type
+	if len(l.Types) > 0 {
+		l.Type = l.Types[0]
+	}
+	return nil
+}
+
+// describe names the location and the config file it came from, for error messages
+func (l *Location) describe() string {
+	if l.Source == "" {
+		return l.DisplayName()
+	}
+	return fmt.Sprintf("%s (from %s)", l.DisplayName(), l.Source)
 }
 
+// DisplayName returns the name of the location, or its path when it has no name
+func (l *Location) DisplayName() string {
+	if l.Name != "" {
+		return l.Name
+	}
+	if l.Path != "" {
+		return l.Path
+	}
+	return l.Location
+}
+
+// ProjectTypes returns every project type of the location
+func (l *Location) ProjectTypes() []string {
+	if len(l.Types) > 0 {
+		return l.Types
+	}
+	if l.Type != "" {
+		return []string{l.Type}
+	}
+	return nil
+}
+
+// HasType reports whether the location has the given project type
+func (l *Location) HasType(name string) bool {
+	for _, projectType := range l.ProjectTypes() {
+		if projectType == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandDirectory returns the directory a command of the location runs in
+func (l *Location) CommandDirectory(command Command) string {
+	if command.Cwd == "" {
+		return l.Location
+	}
+	return filepath.Join(l.Location, command.Cwd)
+}
+
+// stringList is a list of strings that may be written as a single string in YAML
+type stringList []string
+
+func (s *stringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var single string
+		if err := value.Decode(&single); err != nil {
+			return err
+		}
+		*s = stringList{single}
+		return nil
+	}
+
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// TypeNone disables project type detection for a location
+const TypeNone = "none"
+
+// LoadConfig reads a config file together with the files it extends and
+// includes, expands its locations and adds the commands of their project types
 func LoadConfig(configPath string) (*Config, error) {
-	data, err := os.ReadFile(configPath)
+	return LoadConfigContext(context.Background(), configPath)
+}
+
+// LoadConfigContext is LoadConfig with a context. Cancelling ctx stops the
+// project type parsers still running and makes loading fail with ctx.Err().
+func LoadConfigContext(ctx context.Context, configPath string) (*Config, error) {
+	configPath, err := filepath.Abs(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, fmt.Errorf("failed to resolve config directory: %w", err)
 	}
 
-	var config Config
-	err = yaml.Unmarshal(data, &config)
+	config, err := loadWithOverlay(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+		return nil, err
+	}
+
+	// Merge the config files of parent directories when asked to
+	if config.MergeParents && !config.Root {
+		config, err = mergeParentConfigs(config, configPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	configDir := filepath.Dir(configPath)
+	config.Dir = configDir
+
+	// Locations of files in other directories are shown relative to this config file
+	for i := range config.Locations {
+		location := &config.Locations[i]
+		if filepath.Dir(location.Source) != configDir {
+			location.Path = relativePath(location.Location, configDir)
+		}
 	}
 
 	// Expand glob patterns in locations
-	expandedLocations, err := ExpandGlobPatterns(config.Locations)
+	expandedLocations, err := expandGlobPatterns(config.Locations, configDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to expand glob patterns: %w", err)
 	}
 	config.Locations = expandedLocations
 
+	// Add the members of native workspaces
+	members, err := workspaceLocations(config)
+	if err != nil {
+		return nil, err
+	}
+	config.Locations = append(config.Locations, members...)
+
+	// Add project directories found by the discover section
+	discovered, err := discoverLocations(config)
+	if err != nil {
+		return nil, err
+	}
+	config.Locations = append(config.Locations, discovered...)
+
+	// Prefix the locations of nested projects with the project name
+	prefixProjectNames(config)
+
+	// Give every location a unique id, before filtering so ids do not depend on only and hide
+	if err := assignLocationIDs(config); err != nil {
+		return nil, err
+	}
+
+	// Focus on the locations selected by only and hide
+	if err := filterLocations(config); err != nil {
+		return nil, err
+	}
+
 	// Process project types and add their commands
-	if err := processProjectTypes(&config); err != nil {
+	if err := processProjectTypes(ctx, config); err != nil {
 		return nil, fmt.Errorf("failed to process project types: %w", err)
 	}
 
-	return &config, nil
+	if err := sortCommands(config); err != nil {
+		return nil, err
+	}
+
+	warnDuplicateNames(config)
+
+	return config, nil
 }
 
-// processProjectTypes processes project types and adds their commands to locations
-func processProjectTypes(config *Config) error {
-	for i, location := range config.Locations {
-		if location.Type == "" {
-			continue
+// ResolvePath returns the absolute form of a location directory.
+// Relative directories are anchored at the directory of the config file.
+func (c *Config) ResolvePath(directory string) string {
+	return expandPath(directory, c.Dir)
+}
+
+// parseWorkers is how many locations have their project types parsed at the same time
+var parseWorkers = max(runtime.NumCPU(), 4)
+
+// processProjectTypes processes project types and adds their commands to locations.
+// Locations without a type get every detected type, unless the type is "none".
+// When a location has several types, command keys are namespaced as "type:key".
+// A parser failing or timing out is recorded as a warning and the location keeps
+// its other commands.
+//
+// Locations are processed by a bounded pool of workers, so one slow parser does
+// not hold up the others. Warnings are recorded in location order.
+func processProjectTypes(ctx context.Context, config *Config) error {
+	type result struct {
+		warnings []string
+		err      error
+	}
+	results := make([]result, len(config.Locations))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(parseWorkers, len(config.Locations)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].warnings, results[i].err = processLocation(ctx, &config.Locations[i])
+			}
+		}()
+	}
+
+feed:
+	for i := range config.Locations {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
 		}
+	}
+	close(jobs)
+	wg.Wait()
 
-		// Get the project type
-		projectType, err := projecttypes.GetProjectType(location.Type)
-		if err != nil {
-			return fmt.Errorf("location %s has invalid type: %w", location.Location, err)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for i, result := range results {
+		if result.err != nil {
+			return result.err
+		}
+		for _, warning := range result.warnings {
+			config.warn(&config.Locations[i], warning)
 		}
+	}
+	return nil
+}
+
+// processLocation adds the commands of the project types of a location to it,
+// returning the failures of its parsers as warnings
+func processLocation(ctx context.Context, location *Location) ([]string, error) {
+	if location.Type == TypeNone {
+		return nil, nil
+	}
 
-		// Check if the project type config file exists in the location
-		configFile := filepath.Join(location.Location, projectType.DetectConfigFile())
-		if !fileExists(configFile) {
-			// If no config file found, just keep the existing commands
+	projectTypes, err := resolveProjectTypes(location)
+	if err != nil {
+		return nil, err
+	}
+	if len(projectTypes) == 0 {
+		return nil, nil
+	}
+
+	var warnings []string
+	namespaced := len(projectTypes) > 1
+	seen := make(map[string]bool)
+	var generated []Command
+	for _, projectType := range projectTypes {
+		commands, err := projectTypeCommands(ctx, projectType, location.Location)
+		if err != nil {
+			// One failing parser leaves out its commands, not the whole config
+			warnings = append(warnings, fmt.Sprintf("failed to parse %s commands for location %s: %v", projectType.Name(), location.describe(), err))
 			continue
 		}
 
-		// For configurable project types, get the full commands directly
-		if configurableType, ok := projectType.(*projecttypes.ConfigurableProjectType); ok {
-			// Get all commands as a map
-			commands, err := configurableType.GetAllCommands(location.Location)
-			if err != nil {
-				return fmt.Errorf("failed to parse commands for location %s: %w", location.Location, err)
+		for _, cmd := range commands {
+			// Several types may produce the same command, keep the first
+			if seen[cmd.Command] {
+				continue
 			}
+			seen[cmd.Command] = true
 
-			// Convert to command list format
-			var commandList []string
-			for _, cmd := range commands {
-				commandList = append(commandList, cmd)
+			key := cmd.Key
+			if namespaced {
+				key = projectType.Name() + ":" + key
 			}
+			generated = append(generated, Command{
+				Run:         cmd.Command,
+				Description: cmd.Description,
+				Cwd:         cmd.Cwd,
+				Env:         cmd.Env,
+				Tags:        cmd.Tags,
+				Key:         key,
+				Parser:      cmd.Source,
+				Type:        projectType.Name(),
+			})
+		}
+	}
+
+	// Merge with existing commands
+	location.Commands = mergeCommands(location.Commands, generated)
+	return warnings, nil
+}
+
+// mergeCommands merges declared commands with the commands generated from project types.
+// Declared commands naming a generated key (e.g. "build" in an npm location) are resolved
+// to the generated command, keeping their other fields; details the declaration leaves
+// out (description, cwd, env, tags) come from the parser. Declared commands that are full
+// shell lines are kept as written, and take the key, parser and details of a generated
+// command with the same line. Generated commands that were declared are not repeated.
+func mergeCommands(declared, generated []Command) []Command {
+	used := make([]bool, len(generated))
+	merged := make([]Command, 0, len(declared)+len(generated))
 
-			// Merge with existing commands
-			allCommands := append(location.Commands, commandList...)
-			config.Locations[i].Commands = allCommands
-		} else {
-			// Fallback to old behavior for backward compatibility
-			// Parse commands from the project type
-			projectCommands, err := projectType.ParseCommands(configFile)
-			if err != nil {
-				return fmt.Errorf("failed to parse commands for location %s: %w", location.Location, err)
+	for _, cmd := range declared {
+		if i, ok := findGeneratedKey(generated, cmd.Run); ok && !used[i] {
+			used[i] = true
+			resolved := cmd
+			resolved.Run = generated[i].Run
+			resolved.Key = generated[i].Key
+			resolved.Parser = generated[i].Parser
+			resolved.Type = generated[i].Type
+			inheritDetails(&resolved, generated[i])
+			merged = append(merged, resolved)
+			continue
+		}
+
+		// A restated generated command line is still that command, findable by its key
+		restated := cmd
+		for i, gen := range generated {
+			if gen.Run != cmd.Run {
+				continue
 			}
+			if restated.Key == "" {
+				restated.Key = gen.Key
+				restated.Parser = gen.Parser
+				restated.Type = gen.Type
+				inheritDetails(&restated, gen)
+			}
+			used[i] = true
+		}
+		merged = append(merged, restated)
+	}
+
+	for i, cmd := range generated {
+		if !used[i] {
+			merged = append(merged, cmd)
+		}
+	}
+
+	return merged
+}
 
-			// Prefix the commands with the project type command prefix
-			var prefixedCommands []string
-			for _, cmd := range projectCommands {
-				if projectType.GetCommandPrefix() != "" {
-					prefixedCommands = append(prefixedCommands, fmt.Sprintf("%s %s", projectType.GetCommandPrefix(), cmd))
-				} else {
-					prefixedCommands = append(prefixedCommands, cmd)
-				}
+// inheritDetails fills in the details a declared command leaves out from the
+// generated command it resolves to. Env variables are merged, declared ones win.
+func inheritDetails(cmd *Command, generated Command) {
+	if cmd.Description == "" {
+		cmd.Description = generated.Description
+	}
+	if cmd.Cwd == "" {
+		cmd.Cwd = generated.Cwd
+	}
+	if len(cmd.Tags) == 0 {
+		cmd.Tags = generated.Tags
+	}
+	if len(generated.Env) > 0 {
+		env := make(map[string]string, len(generated.Env)+len(cmd.Env))
+		for key, value := range generated.Env {
+			env[key] = value
+		}
+		for key, value := range cmd.Env {
+			env[key] = value
+		}
+		cmd.Env = env
+	}
+}
+
+// findGeneratedKey finds the generated command a declared entry refers to by key.
+// Namespaced keys ("npm:lint") match exactly, bare keys only when a single type provides them.
+func findGeneratedKey(generated []Command, entry string) (int, bool) {
+	if entry == "" || strings.ContainsAny(entry, " \t") {
+		return 0, false
+	}
+
+	for i, cmd := range generated {
+		if cmd.Key == entry {
+			return i, true
+		}
+	}
+
+	found := -1
+	for i, cmd := range generated {
+		if strings.TrimPrefix(cmd.Key, cmd.Type+":") == entry {
+			if found >= 0 {
+				return 0, false
 			}
+			found = i
+		}
+	}
+	return found, found >= 0
+}
 
-			// Merge with existing commands
-			allCommands := append(location.Commands, prefixedCommands...)
-			config.Locations[i].Commands = allCommands
+// resolveProjectTypes returns the project types of a location, detecting them when none are declared.
+// Detected types are recorded on the location.
+func resolveProjectTypes(location *Location) ([]projecttypes.ProjectType, error) {
+	names := location.ProjectTypes()
+	if len(names) == 0 {
+		detected, err := projecttypes.DiscoverProjectTypes(location.Location)
+		if err != nil {
+			// Nothing detected, keep the declared commands only
+			return nil, nil
 		}
+
+		for _, projectType := range detected {
+			location.Types = append(location.Types, projectType.Name())
+		}
+		location.Type = location.Types[0]
+		return detected, nil
 	}
 
-	return nil
+	var projectTypes []projecttypes.ProjectType
+	for _, name := range names {
+		projectType, err := projecttypes.GetProjectType(name)
+		if err != nil {
+			return nil, fmt.Errorf("location %s has invalid type: %w", location.describe(), err)
+		}
+		projectTypes = append(projectTypes, projectType)
+	}
+	return projectTypes, nil
+}
+
+// projectTypeCommands returns the commands a project type provides for a directory.
+// No commands are returned when the directory lacks the project type's files.
+func projectTypeCommands(ctx context.Context, projectType projecttypes.ProjectType, directory string) ([]parsers.FormattedCommand, error) {
+	// For configurable project types, get the full commands directly
+	if configurableType, ok := projectType.(*projecttypes.ConfigurableProjectType); ok {
+		if !configurableType.CanHandleDirectory(directory) {
+			return nil, nil
+		}
+		return configurableType.GetCommandsWithSource(ctx, directory)
+	}
+
+	// Fallback to old behavior for backward compatibility
+	// Check if the project type config file exists in the location
+	configFile := filepath.Join(directory, projectType.DetectConfigFile())
+	if !fileExists(configFile) {
+		return nil, nil
+	}
+
+	// Parse commands from the project type
+	projectCommands, err := projectType.ParseCommands(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefix the commands with the project type command prefix
+	var commands []parsers.FormattedCommand
+	for _, cmd := range projectCommands {
+		command := cmd
+		if projectType.GetCommandPrefix() != "" {
+			command = fmt.Sprintf("%s %s", projectType.GetCommandPrefix(), cmd)
+		}
+		commands = append(commands, parsers.FormattedCommand{Key: cmd, Command: command})
+	}
+	return commands, nil
 }
 
 // fileExists checks if a file exists
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -75,8 +76,11 @@ func TestLoadConfigWithGlobExpansion(t *testing.T) {
 		if loc.Name != "services" {
 			t.Errorf("Location[%d].Name = %q, expected %q", i, loc.Name, "services")
 		}
-		if loc.Location != expectedLocations[i] {
-			t.Errorf("Location[%d].Location = %q, expected %q", i, loc.Location, expectedLocations[i])
+		if loc.Location != filepath.Join(tmpDir, expectedLocations[i]) {
+			t.Errorf("Location[%d].Location = %q, expected %q", i, loc.Location, filepath.Join(tmpDir, expectedLocations[i]))
+		}
+		if loc.Path != expectedLocations[i] {
+			t.Errorf("Location[%d].Path = %q, expected %q", i, loc.Path, expectedLocations[i])
 		}
 		if loc.Type != "npm" {
 			t.Errorf("Location[%d].Type = %q, expected %q", i, loc.Type, "npm")
@@ -86,8 +90,8 @@ func TestLoadConfigWithGlobExpansion(t *testing.T) {
 			t.Errorf("Location[%d] has %d commands, expected %d", i, len(loc.Commands), len(expectedCommands))
 		}
 		for j, cmd := range loc.Commands {
-			if cmd != expectedCommands[j] {
-				t.Errorf("Location[%d].Commands[%d] = %q, expected %q", i, j, cmd, expectedCommands[j])
+			if cmd.Run != expectedCommands[j] {
+				t.Errorf("Location[%d].Commands[%d] = %q, expected %q", i, j, cmd.Run, expectedCommands[j])
 			}
 		}
 	}
@@ -103,7 +107,7 @@ func TestLoadConfigWithInvalidGlobPattern(t *testing.T) {
 	// Create config file with invalid glob pattern
 	configYAML := `locations:
   - name: "services"
-    location: "foo/*/bar/*"
+    location: "foo/{bar,baz/*"
     type: "npm"
     commands:
       - "start"`
@@ -129,10 +133,276 @@ func TestLoadConfigWithInvalidGlobPattern(t *testing.T) {
 	// Load config should fail with validation error
 	_, err = LoadConfig(configPath)
 	if err == nil {
-		t.Errorf("Expected error for invalid glob pattern, but got none")
+		t.Fatalf("Expected error for invalid glob pattern, but got none")
+	}
+
+	if !strings.Contains(err.Error(), "unbalanced braces") {
+		t.Errorf("Expected error message about unbalanced braces, got: %v", err)
+	}
+}
+
+func TestLoadConfigAutoDetectsProjectTypes(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// A glob over packages of different languages, plus an opted-out location
+	files := map[string]string{
+		"packages/api/go.mod":        "module example.com/api\n",
+		"packages/engine/Cargo.toml": "[package]\nname = \"engine\"\n",
+		"packages/docs/README.md":    "docs\n",
+		"tools/go.mod":               "module example.com/tools\n",
+	}
+	for file, content := range files {
+		path := filepath.Join(tmpDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", file, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+
+	configYAML := `locations:
+  - location: "packages/*"
+  - name: "tools"
+    location: "tools"
+    type: "none"
+    commands:
+      - "make release"`
+
+	configPath := filepath.Join(tmpDir, ".gopmrc")
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	expected := []struct {
+		name       string
+		typeName   string
+		hasCommand string
+		noCommands bool
+	}{
+		{name: "api", typeName: "go"},
+		{name: "docs", typeName: "", noCommands: true},
+		{name: "engine", typeName: "rust", hasCommand: "cargo test"},
+		{name: "tools", typeName: TypeNone, hasCommand: "make release"},
+	}
+
+	if len(config.Locations) != len(expected) {
+		t.Fatalf("Expected %d locations, got %d", len(expected), len(config.Locations))
+	}
+
+	for i, exp := range expected {
+		loc := config.Locations[i]
+		if loc.Name != exp.name {
+			t.Errorf("Location[%d].Name = %q, expected %q", i, loc.Name, exp.name)
+		}
+		if loc.Type != exp.typeName {
+			t.Errorf("Location[%d].Type = %q, expected %q", i, loc.Type, exp.typeName)
+		}
+		if exp.noCommands && len(loc.Commands) != 0 {
+			t.Errorf("Location[%d] has commands %v, expected none", i, loc.Commands)
+		}
+		if exp.typeName != "" && exp.typeName != TypeNone && len(loc.Commands) == 0 {
+			t.Errorf("Location[%d] has no commands from detected type %q", i, exp.typeName)
+		}
+		if exp.typeName == TypeNone && len(loc.Commands) != 1 {
+			t.Errorf("Location[%d] has commands %v, expected only the declared one", i, loc.Commands)
+		}
+		if exp.hasCommand != "" {
+			found := false
+			for _, cmd := range loc.Commands {
+				if cmd.Run == exp.hasCommand {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Location[%d] missing command %q, got %v", i, exp.hasCommand, loc.Commands)
+			}
+		}
+	}
+}
+
+func TestLoadConfigMultipleProjectTypes(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"app/package.json": `{"scripts": {"lint": "eslint ."}}`,
+		"app/Makefile":     "lint:\n\t@echo lint\n",
+	}
+	for file, content := range files {
+		path := filepath.Join(tmpDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", file, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+
+	configYAML := `locations:
+  - name: "detected"
+    location: "app"
+  - name: "declared"
+    location: "app"
+    type: [make, npm]
+  - name: "single"
+    location: "app"
+    type: make`
+
+	configPath := filepath.Join(tmpDir, ".gopmrc")
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
 	}
 
-	if !strings.Contains(err.Error(), "multiple asterisks not supported") {
-		t.Errorf("Expected error message about multiple asterisks, got: %v", err)
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		types []string
+		keys  map[string]Command
+	}{
+		{
+			types: []string{"npm", "make"},
+			keys: map[string]Command{
+				"npm run lint": {Run: "npm run lint", Key: "npm:lint", Parser: "package_json_scripts", Type: "npm"},
+				"make lint":    {Run: "make lint", Key: "make:lint", Parser: "makefile", Type: "make"},
+			},
+		},
+		{
+			types: []string{"make", "npm"},
+			keys: map[string]Command{
+				"npm run lint": {Run: "npm run lint", Key: "npm:lint", Parser: "package_json_scripts", Type: "npm"},
+				"make lint":    {Run: "make lint", Key: "make:lint", Parser: "makefile", Type: "make"},
+			},
+		},
+		{
+			types: []string{"make"},
+			keys: map[string]Command{
+				"make lint": {Run: "make lint", Key: "lint", Parser: "makefile", Type: "make"},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		loc := config.Locations[i]
+		if strings.Join(loc.ProjectTypes(), ",") != strings.Join(tt.types, ",") {
+			t.Errorf("%s: ProjectTypes() = %v, expected %v", loc.Name, loc.ProjectTypes(), tt.types)
+		}
+		if loc.Type != tt.types[0] {
+			t.Errorf("%s: Type = %q, expected %q", loc.Name, loc.Type, tt.types[0])
+		}
+		for run, expected := range tt.keys {
+			found := false
+			for _, cmd := range loc.Commands {
+				if cmd.Run == run {
+					found = true
+					if !reflect.DeepEqual(cmd, expected) {
+						t.Errorf("%s: command %q = %+v, expected %+v", loc.Name, run, cmd, expected)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("%s: missing command %q", loc.Name, run)
+			}
+		}
+	}
+}
+
+func TestLoadConfigResolvesDeclaredKeys(t *testing.T) {
+	tmpDir := t.TempDir()
+	webDir := filepath.Join(tmpDir, "web")
+	if err := os.MkdirAll(webDir, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	packageJSON := `{"scripts": {"start": "vite", "build": "vite build", "lint": "eslint .", "dev": "vite dev"}}`
+	if err := os.WriteFile(filepath.Join(webDir, "package.json"), []byte(packageJSON), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+
+	configYAML := `locations:
+  - name: "web"
+    location: "web"
+    type: "npm"
+    commands:
+      - "start"
+      - "build"
+      - "npm run lint"
+      - "./deploy.sh"
+      - name: "Dev server"
+        run: "dev"
+        env:
+          PORT: "3000"`
+
+	configPath := filepath.Join(tmpDir, ".gopmrc")
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	defer os.Chdir(oldWd)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	commands := config.Locations[0].Commands
+	expected := []Command{
+		{Run: "npm run start", Key: "start", Parser: "package_json_scripts", Type: "npm"},
+		{Run: "npm run build", Key: "build", Parser: "package_json_scripts", Type: "npm"},
+		{Run: "npm run lint", Key: "lint", Parser: "package_json_scripts", Type: "npm"},
+		{Run: "./deploy.sh"},
+		{Name: "Dev server", Run: "npm run dev", Env: map[string]string{"PORT": "3000"}, Key: "dev", Parser: "package_json_scripts", Type: "npm"},
+	}
+	if len(commands) < len(expected) {
+		t.Fatalf("Expected at least %d commands, got %v", len(expected), commands)
 	}
-}
\ No newline at end of file
+	for i, exp := range expected {
+		// Every declared command records the file it came from
+		exp.Origin = configPath
+		if !reflect.DeepEqual(commands[i], exp) {
+			t.Errorf("Commands[%d] = %+v, expected %+v", i, commands[i], exp)
+		}
+	}
+
+	// Declared keys must not show up again as generated commands
+	seen := make(map[string]bool)
+	for _, cmd := range commands {
+		if seen[cmd.Run] {
+			t.Errorf("Command %q is listed twice", cmd.Run)
+		}
+		seen[cmd.Run] = true
+		if cmd.Run == "start" || cmd.Run == "build" || cmd.Run == "dev" {
+			t.Errorf("Declared key %q was not resolved", cmd.Run)
+		}
+	}
+}
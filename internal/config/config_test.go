@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"gopkg.in/yaml.v3"
@@ -31,7 +32,7 @@ func TestConfigYAMLParsing(t *testing.T) {
 						Name:     "frontend",
 						Location: "packages/frontend",
 						Type:     "npm",
-						Commands: []string{"start", "build", "test"},
+						Commands: []Command{{Run: "start"}, {Run: "build"}, {Run: "test"}},
 					},
 				},
 			},
@@ -58,13 +59,13 @@ func TestConfigYAMLParsing(t *testing.T) {
 						Name:     "frontend",
 						Location: "packages/frontend",
 						Type:     "npm",
-						Commands: []string{"start", "build"},
+						Commands: []Command{{Run: "start"}, {Run: "build"}},
 					},
 					{
 						Name:     "backend",
 						Location: "packages/backend",
 						Type:     "go",
-						Commands: []string{"run", "test"},
+						Commands: []Command{{Run: "run"}, {Run: "test"}},
 					},
 				},
 			},
@@ -82,7 +83,7 @@ func TestConfigYAMLParsing(t *testing.T) {
 					{
 						Location: "packages/frontend",
 						Type:     "npm",
-						Commands: []string{"start"},
+						Commands: []Command{{Run: "start"}},
 					},
 				},
 			},
@@ -101,7 +102,7 @@ func TestConfigYAMLParsing(t *testing.T) {
 					{
 						Name:     "scripts",
 						Location: "scripts",
-						Commands: []string{"deploy.sh", "backup.sh"},
+						Commands: []Command{{Run: "deploy.sh"}, {Run: "backup.sh"}},
 					},
 				},
 			},
@@ -124,6 +125,58 @@ func TestConfigYAMLParsing(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "structured commands mixed with strings",
+			yaml: `locations:
+  - name: "web"
+    location: "web"
+    commands:
+      - "npm start"
+      - name: "storybook"
+        run: "npx storybook dev"
+        description: "Component explorer"
+        cwd: "ui"
+        env:
+          PORT: "6006"
+        shell: "bash"`,
+			expected: Config{
+				Locations: []Location{
+					{
+						Name:     "web",
+						Location: "web",
+						Commands: []Command{
+							{Run: "npm start"},
+							{
+								Name:        "storybook",
+								Run:         "npx storybook dev",
+								Description: "Component explorer",
+								Cwd:         "ui",
+								Env:         map[string]string{"PORT": "6006"},
+								Shell:       "bash",
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "structured command without run",
+			yaml: `locations:
+  - location: "web"
+    commands:
+      - name: "storybook"`,
+			wantErr: true,
+		},
+		{
+			name: "structured command outside its location",
+			yaml: `locations:
+  - location: "web"
+    commands:
+      - run: "ls"
+        cwd: "../api"`,
+			wantErr: true,
+		},
 		{
 			name: "empty config",
 			yaml: `locations: []`,
@@ -165,8 +218,8 @@ func TestConfigYAMLParsing(t *testing.T) {
 						t.Errorf("Location[%d] has %d commands, expected %d", i, len(loc.Commands), len(expected.Commands))
 					}
 					for j, cmd := range loc.Commands {
-						if cmd != expected.Commands[j] {
-							t.Errorf("Location[%d].Commands[%d] = %q, expected %q", i, j, cmd, expected.Commands[j])
+						if j < len(expected.Commands) && !reflect.DeepEqual(cmd, expected.Commands[j]) {
+							t.Errorf("Location[%d].Commands[%d] = %+v, expected %+v", i, j, cmd, expected.Commands[j])
 						}
 					}
 				}
@@ -200,7 +253,7 @@ func TestLoadConfig(t *testing.T) {
 						Name:     "frontend",
 						Location: "packages/frontend",
 						Type:     "npm",
-						Commands: []string{"start", "build"},
+						Commands: []Command{{Run: "start"}, {Run: "build"}},
 					},
 				},
 			},
@@ -273,8 +326,8 @@ func TestLoadConfig(t *testing.T) {
 				if loc.Name != expected.Name {
 					t.Errorf("Location[%d].Name = %q, expected %q", i, loc.Name, expected.Name)
 				}
-				if loc.Location != expected.Location {
-					t.Errorf("Location[%d].Location = %q, expected %q", i, loc.Location, expected.Location)
+				if expectedLocation := filepath.Join(tmpDir, expected.Location); loc.Location != expectedLocation {
+					t.Errorf("Location[%d].Location = %q, expected %q", i, loc.Location, expectedLocation)
 				}
 				if loc.Type != expected.Type {
 					t.Errorf("Location[%d].Type = %q, expected %q", i, loc.Type, expected.Type)
@@ -283,11 +336,59 @@ func TestLoadConfig(t *testing.T) {
 					t.Errorf("Location[%d] has %d commands, expected %d", i, len(loc.Commands), len(expected.Commands))
 				}
 				for j, cmd := range loc.Commands {
-					if cmd != expected.Commands[j] {
-						t.Errorf("Location[%d].Commands[%d] = %q, expected %q", i, j, cmd, expected.Commands[j])
+					if cmd.Run != expected.Commands[j].Run {
+						t.Errorf("Location[%d].Commands[%d] = %q, expected %q", i, j, cmd.Run, expected.Commands[j].Run)
 					}
 				}
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestMergeCommandsInheritsDetails(t *testing.T) {
+	generated := []Command{{
+		Run:         "npm run e2e",
+		Description: "End to end tests",
+		Cwd:         "e2e",
+		Env:         map[string]string{"CI": "1", "HEADLESS": "1"},
+		Tags:        []string{"test"},
+		Key:         "e2e",
+		Parser:      "parser_command",
+		Type:        "npm",
+	}}
+	declared := []Command{{Run: "e2e", Name: "e2e tests", Env: map[string]string{"HEADLESS": "0"}}}
+
+	merged := mergeCommands(declared, generated)
+	expected := []Command{{
+		Name:        "e2e tests",
+		Run:         "npm run e2e",
+		Description: "End to end tests",
+		Cwd:         "e2e",
+		Env:         map[string]string{"CI": "1", "HEADLESS": "0"},
+		Tags:        []string{"test"},
+		Key:         "e2e",
+		Parser:      "parser_command",
+		Type:        "npm",
+	}}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("mergeCommands() = %+v, expected %+v", merged, expected)
+	}
+}
+
+func TestMergeCommandsRestatedCommandLine(t *testing.T) {
+	generated := []Command{
+		{Run: "npm run lint", Key: "lint", Parser: "package_json_scripts", Type: "npm"},
+		{Run: "npm run test", Key: "test", Parser: "package_json_scripts", Type: "npm"},
+	}
+	declared := []Command{{Name: "lint all", Run: "npm run lint"}, {Run: "./deploy.sh"}}
+
+	merged := mergeCommands(declared, generated)
+	expected := []Command{
+		{Name: "lint all", Run: "npm run lint", Key: "lint", Parser: "package_json_scripts", Type: "npm"},
+		{Run: "./deploy.sh"},
+		{Run: "npm run test", Key: "test", Parser: "package_json_scripts", Type: "npm"},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("mergeCommands() = %+v, expected %+v", merged, expected)
+	}
+}
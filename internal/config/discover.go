@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/martin/go-pm/internal/parsers"
+)
+
+// DefaultDiscoverDepth is how many directories below the root discovery looks
+// when max_depth is not set
+const DefaultDiscoverDepth = 3
+
+// Discover configures marker based location discovery. Every directory below
+// Root containing one of the Markers becomes a location with detected types.
+type Discover struct {
+	// Root is the directory to search, relative to the config file (default ".")
+	Root string `yaml:"root,omitempty"`
+
+	// MaxDepth limits how deep below Root to search, see DefaultDiscoverDepth
+	MaxDepth int `yaml:"max_depth,omitempty"`
+
+	// Markers are the files marking a project directory. They default to the
+	// detect_files of every configured parser.
+	Markers []string `yaml:"markers,omitempty"`
+
+	// Ignore lists glob patterns, relative to the config file, of directories to skip
+	Ignore []string `yaml:"ignore,omitempty"`
+}
+
+// discoverLocations walks the discover root and returns a location for every
+// directory containing a marker file. node_modules, .git and .gitignore matches
+// are skipped like in glob expansion. Directories that are already a location
+// are left out.
+func discoverLocations(config *Config) ([]Location, error) {
+	discover := config.Discover
+	if discover == nil {
+		return nil, nil
+	}
+
+	for _, pattern := range discover.Ignore {
+		if err := validateGlobPattern(pattern); err != nil {
+			return nil, fmt.Errorf("invalid discover ignore: %w", err)
+		}
+	}
+	if discover.MaxDepth < 0 {
+		return nil, fmt.Errorf("invalid discover max_depth %d", discover.MaxDepth)
+	}
+
+	markers := discover.Markers
+	if len(markers) == 0 {
+		parsersFile, err := parsers.LoadParsersConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load parsers for discovery: %w", err)
+		}
+		markers = parsersFile.DetectFiles()
+	}
+
+	maxDepth := discover.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = DefaultDiscoverDepth
+	}
+
+	root := expandPath(discover.Root, config.Dir)
+	if !isDirectory(root) {
+		return nil, fmt.Errorf("discover root %s is not a directory", root)
+	}
+
+	var dirs []string
+	var walk func(dir string, ignore *ignoreMatcher, depth int) error
+	walk = func(dir string, ignore *ignoreMatcher, depth int) error {
+		if isExcluded(dir, discover.Ignore, config.Dir) {
+			return nil
+		}
+		if hasMarker(dir, markers) {
+			dirs = append(dirs, dir)
+		}
+		if depth == maxDepth {
+			return nil
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to discover locations: %w", err)
+		}
+		for _, entry := range entries {
+			child := filepath.Join(dir, entry.Name())
+			if alwaysIgnoredDirs[entry.Name()] || !isDirectory(child) || ignore.ignored(child, true) {
+				continue
+			}
+			if err := walk(child, ignore.enter(child), depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(root, newIgnoreMatcher(config.Dir, root), 0); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, location := range config.Locations {
+		known[location.Location] = true
+	}
+
+	var found []string
+	for _, dir := range dirs {
+		if !known[dir] {
+			found = append(found, dir)
+		}
+	}
+	sort.Strings(found)
+
+	// Discovered locations are named after their directory unless that is ambiguous
+	useBaseNames := uniqueBaseNames(found)
+	locations := make([]Location, 0, len(found))
+	for _, dir := range found {
+		location := Location{
+			Location: dir,
+			Path:     relativePath(dir, config.Dir),
+		}
+		if useBaseNames {
+			location.Name = filepath.Base(dir)
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+// hasMarker reports whether dir contains one of the marker files
+func hasMarker(dir string, markers []string) bool {
+	for _, marker := range markers {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverLocations(t *testing.T) {
+	files := map[string]string{
+		"apps/web/package.json":              `{"scripts": {"dev": "vite"}}`,
+		"services/api/go.mod":                "module api\n",
+		"services/api/internal/tool/go.mod":  "module tool\n",
+		"deep/a/b/c/Cargo.toml":              "[package]\n",
+		"node_modules/left-pad/package.json": `{}`,
+		"examples/demo/go.mod":               "module demo\n",
+		"dist/web/package.json":              `{}`,
+		"docs/README.md":                     "docs\n",
+		".gitignore":                         "dist/\n",
+	}
+
+	tests := []struct {
+		name     string
+		discover Discover
+		declared []string
+		expected []string
+	}{
+		{
+			name:     "default markers and depth",
+			discover: Discover{Ignore: []string{"examples"}},
+			expected: []string{"apps/web", "services/api"},
+		},
+		{
+			name:     "max depth",
+			discover: Discover{MaxDepth: 4, Ignore: []string{"examples"}},
+			expected: []string{"apps/web", "deep/a/b/c", "services/api", "services/api/internal/tool"},
+		},
+		{
+			name:     "custom markers and root",
+			discover: Discover{Root: "services", MaxDepth: 5, Markers: []string{"go.mod"}},
+			expected: []string{"services/api", "services/api/internal/tool"},
+		},
+		{
+			name:     "ignore with double star",
+			discover: Discover{Markers: []string{"go.mod"}, MaxDepth: 5, Ignore: []string{"**/internal"}},
+			expected: []string{"examples/demo", "services/api"},
+		},
+		{
+			name:     "declared locations are not repeated",
+			discover: Discover{Ignore: []string{"examples"}},
+			declared: []string{"services/api"},
+			expected: []string{"apps/web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for file, content := range files {
+				path := filepath.Join(tmpDir, file)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			discover := tt.discover
+			config := &Config{Dir: tmpDir, Discover: &discover}
+			for _, dir := range tt.declared {
+				config.Locations = append(config.Locations, Location{Location: filepath.Join(tmpDir, dir)})
+			}
+
+			locations, err := discoverLocations(config)
+			if err != nil {
+				t.Fatalf("discoverLocations() error = %v", err)
+			}
+
+			if len(locations) != len(tt.expected) {
+				t.Fatalf("discoverLocations() found %d locations, expected %v", len(locations), tt.expected)
+			}
+			for i, location := range locations {
+				if location.Path != tt.expected[i] {
+					t.Errorf("Location[%d].Path = %q, expected %q", i, location.Path, tt.expected[i])
+				}
+				if location.Location != filepath.Join(tmpDir, tt.expected[i]) {
+					t.Errorf("Location[%d].Location = %q, expected it under %s", i, location.Location, tmpDir)
+				}
+			}
+		})
+	}
+}
+
+func TestDiscoverLocationNames(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"apps/api", "services/api"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, dir, "go.mod"), []byte("module api\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &Config{Dir: tmpDir, Discover: &Discover{Markers: []string{"go.mod"}}}
+	locations, err := discoverLocations(config)
+	if err != nil {
+		t.Fatalf("discoverLocations() error = %v", err)
+	}
+
+	// Both directories are called api, so they are shown by path instead
+	for _, location := range locations {
+		if location.Name != "" {
+			t.Errorf("Expected no name for %s, got %q", location.Path, location.Name)
+		}
+	}
+}
+
+func TestLoadConfigWithDiscover(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "packages", "web"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	packageJSON := `{"scripts": {"dev": "vite", "build": "vite build"}}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "packages", "web", "package.json"), []byte(packageJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(tmpDir, ".gopmrc")
+	configYAML := `discover:
+  root: packages
+  markers: [package.json]
+`
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if len(config.Locations) != 1 {
+		t.Fatalf("Expected 1 discovered location, got %d", len(config.Locations))
+	}
+	location := config.Locations[0]
+	if location.Name != "web" || location.Path != filepath.Join("packages", "web") {
+		t.Errorf("Unexpected location %q at %q", location.Name, location.Path)
+	}
+	if location.Type != "npm" {
+		t.Errorf("Expected detected type npm, got %q", location.Type)
+	}
+	found := false
+	for _, cmd := range location.Commands {
+		if cmd.Run == "npm run dev" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected the dev script among %v", location.Commands)
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,8 +9,13 @@ import (
 
 const ConfigFileName = ".gopmrc"
 
+// LocalConfigFileName is the personal, git-ignored overlay merged on top of the
+// config file in the same directory
+const LocalConfigFileName = ".gopmrc.local.yaml"
+
 // FindConfigFile searches for .gopmrc starting from the current working directory
 // and traversing up the directory tree until it finds one or reaches the root.
+// A directory with only a .gopmrc.local.yaml overlay counts as well.
 func FindConfigFile() (string, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -31,6 +37,10 @@ func findConfigFileFromPath(startPath string) (string, error) {
 		if _, err := os.Stat(configPath); err == nil {
 			return configPath, nil
 		}
+		localPath := filepath.Join(currentPath, LocalConfigFileName)
+		if _, err := os.Stat(localPath); err == nil {
+			return localPath, nil
+		}
 		
 		// Move to parent directory
 		parentPath := filepath.Dir(currentPath)
@@ -48,23 +58,16 @@ func findConfigFileFromPath(startPath string) (string, error) {
 
 // LoadConfigFromDiscovery finds and loads the nearest .gopmrc file
 func LoadConfigFromDiscovery() (*Config, error) {
+	return LoadConfigFromDiscoveryContext(context.Background())
+}
+
+// LoadConfigFromDiscoveryContext finds and loads the nearest .gopmrc file,
+// see LoadConfigContext
+func LoadConfigFromDiscoveryContext(ctx context.Context) (*Config, error) {
 	configPath, err := FindConfigFile()
 	if err != nil {
 		return nil, err
 	}
-	
-	// Change to the directory containing the config file for glob expansion
-	configDir := filepath.Dir(configPath)
-	oldWd, err := os.Getwd()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get current working directory: %w", err)
-	}
-	defer os.Chdir(oldWd)
-	
-	err = os.Chdir(configDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to change to config directory %q: %w", configDir, err)
-	}
-	
-	return LoadConfig(configPath)
-}
\ No newline at end of file
+
+	return LoadConfigContext(ctx, configPath)
+}
@@ -84,6 +84,29 @@ func TestFindConfigFile(t *testing.T) {
 			expectedConfig: "project/.gopmrc",
 			wantErr:        false,
 		},
+		{
+			name:      "local overlay without a config file",
+			setupDirs: []string{"project", "project/subdir"},
+			setupConfigs: map[string]string{
+				"project/.gopmrc.local.yaml": `locations:
+  - location: "scratch"
+    commands: ["ls"]`,
+			},
+			startDir:       "project/subdir",
+			expectedConfig: "project/.gopmrc.local.yaml",
+			wantErr:        false,
+		},
+		{
+			name:      "config file is preferred over the overlay",
+			setupDirs: []string{"project"},
+			setupConfigs: map[string]string{
+				"project/.gopmrc":            `locations: []`,
+				"project/.gopmrc.local.yaml": `only: [api]`,
+			},
+			startDir:       "project",
+			expectedConfig: "project/.gopmrc",
+			wantErr:        false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -214,7 +237,13 @@ func TestLoadConfigFromDiscovery(t *testing.T) {
 		t.Errorf("Expected location name 'frontend', got %q", config.Locations[0].Name)
 	}
 
-	if config.Locations[0].Location != "packages/frontend" {
-		t.Errorf("Expected location path 'packages/frontend', got %q", config.Locations[0].Location)
+	// Locations are anchored at the config file, not at the working directory
+	expectedPath := filepath.Join(tmpDir, "project", "packages", "frontend")
+	if config.Locations[0].Location != expectedPath {
+		t.Errorf("Expected location path %q, got %q", expectedPath, config.Locations[0].Location)
+	}
+
+	if cwd, _ := os.Getwd(); cwd != nestedPath {
+		t.Errorf("Expected working directory to stay %q, got %q", nestedPath, cwd)
 	}
 }
\ No newline at end of file
@@ -3,23 +3,37 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
 )
 
+// ExpandGlobPatterns replaces locations containing a glob pattern with one location
+// per matching directory. Relative patterns are matched from the working directory.
 func ExpandGlobPatterns(locations []Location) ([]Location, error) {
+	return expandGlobPatterns(locations, "")
+}
+
+// expandGlobPatterns expands glob patterns, recording matches relative to baseDir for display.
+// Exclude patterns and .gitignore files are resolved from baseDir as well.
+func expandGlobPatterns(locations []Location, baseDir string) ([]Location, error) {
 	var result []Location
 	
 	for _, loc := range locations {
 		// Check if location contains glob pattern
-		if strings.Contains(loc.Location, "*") {
+		if hasGlobMeta(loc.Location) {
 			// Validate glob pattern before expansion
 			if err := validateGlobPattern(loc.Location); err != nil {
-				return nil, err
+				return nil, fmt.Errorf("location %s: %w", loc.describe(), err)
+			}
+			for _, exclude := range loc.Exclude {
+				if err := validateGlobPattern(exclude); err != nil {
+					return nil, fmt.Errorf("invalid exclude for location %s: %w", loc.describe(), err)
+				}
 			}
 			
-			expanded, err := expandSingleGlob(loc)
+			expanded, err := expandSingleGlob(loc, baseDir)
 			if err != nil {
 				return nil, err
 			}
@@ -33,68 +47,306 @@ func ExpandGlobPatterns(locations []Location) ([]Location, error) {
 	return result, nil
 }
 
+// hasGlobMeta reports whether pattern contains wildcards, classes or braces
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[{")
+}
+
+// validateGlobPattern checks that braces are balanced, that ** only appears as a
+// whole path segment and that every segment is a valid path.Match pattern
 func validateGlobPattern(pattern string) error {
-	// Count asterisks
-	asteriskCount := strings.Count(pattern, "*")
-	if asteriskCount > 1 {
-		return fmt.Errorf("invalid glob pattern %q: multiple asterisks not supported, only simple patterns like 'path/*' are allowed", pattern)
+	alternatives, err := expandBraces(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+	
+	for _, alternative := range alternatives {
+		for _, segment := range strings.Split(filepath.ToSlash(alternative), "/") {
+			if segment == "**" {
+				continue
+			}
+			if strings.Contains(segment, "**") {
+				return fmt.Errorf("invalid glob pattern %q: ** must be a whole path segment", pattern)
+			}
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+			}
+		}
 	}
 	
-	// Check if asterisk is at the end of the pattern
-	if !strings.HasSuffix(pattern, "*") {
-		return fmt.Errorf("invalid glob pattern %q: asterisk must be at the end of the pattern", pattern)
+	return nil
+}
+
+// expandBraces expands {a,b} alternatives, including nested ones, into plain patterns
+func expandBraces(pattern string) ([]string, error) {
+	open := strings.IndexByte(pattern, '{')
+	if open < 0 {
+		if strings.IndexByte(pattern, '}') >= 0 {
+			return nil, fmt.Errorf("unbalanced braces")
+		}
+		return []string{pattern}, nil
 	}
 	
-	// Check if asterisk is preceded by a slash
-	if !strings.HasSuffix(pattern, "/*") {
-		return fmt.Errorf("invalid glob pattern %q: asterisk must be preceded by a slash (e.g., 'path/*')", pattern)
+	// Find the matching close brace and the top level commas in between
+	depth := 0
+	closing := -1
+	commas := []int{}
+	for i := open; i < len(pattern) && closing < 0; i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				closing = i
+			}
+		case ',':
+			if depth == 1 {
+				commas = append(commas, i)
+			}
+		}
+	}
+	if closing < 0 {
+		return nil, fmt.Errorf("unbalanced braces")
 	}
 	
-	return nil
+	prefix, suffix := pattern[:open], pattern[closing+1:]
+	var alternatives []string
+	start := open + 1
+	for _, end := range append(commas, closing) {
+		alternatives = append(alternatives, pattern[start:end])
+		start = end + 1
+	}
+	
+	var result []string
+	for _, alternative := range alternatives {
+		expanded, err := expandBraces(prefix + alternative + suffix)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, expanded...)
+	}
+	return result, nil
 }
 
-func expandSingleGlob(loc Location) ([]Location, error) {
-	matches, err := filepath.Glob(loc.Location)
+func expandSingleGlob(loc Location, baseDir string) ([]Location, error) {
+	patterns, err := expandBraces(loc.Location)
 	if err != nil {
 		return nil, err
 	}
 	
-	// Filter to only include directories
+	// Collect matching directories once, even when alternatives overlap
+	seen := make(map[string]bool)
 	var dirMatches []string
-	for _, match := range matches {
-		if isDirectory(match) {
-			dirMatches = append(dirMatches, match)
+	for _, pattern := range patterns {
+		matches, err := globDirs(pattern, baseDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if !seen[match] && !isExcluded(match, loc.Exclude, baseDir) {
+				seen[match] = true
+				dirMatches = append(dirMatches, match)
+			}
 		}
 	}
 	
 	// Sort matches for consistent output
 	sort.Strings(dirMatches)
 	
+	// Name unnamed matches after their directory, unless that would be ambiguous;
+	// those fall back to their path
+	useBaseNames := loc.Name == "" || loc.Name == filepath.Base(filepath.Dir(loc.Location))
+	uniqueBases := uniqueBaseNames(dirMatches)
+	useBaseNames = useBaseNames && uniqueBases
+	
+	pattern := loc.Path
+	if pattern == "" {
+		pattern = loc.Location
+	}
+
 	// Create new Location for each match
 	var result []Location
 	for _, match := range dirMatches {
-		// Use the directory name as the name if original name is generic
 		name := loc.Name
-		if loc.Name == "" || loc.Name == filepath.Base(filepath.Dir(loc.Location)) {
+		if useBaseNames {
 			name = filepath.Base(match)
 		}
 		
-		newLoc := Location{
-			Name:     name,
-			Location: match,
-			Type:     loc.Type,
-			Commands: append([]string{}, loc.Commands...),
+		newLoc := loc
+		newLoc.Name = name
+		if loc.ID != "" {
+			// The id of a glob location prefixes the ids of its matches
+			newLoc.ID = loc.ID + "/" + globMatchID(match, baseDir, uniqueBases)
 		}
+		newLoc.Location = match
+		newLoc.Path = relativePath(match, baseDir)
+		newLoc.Pattern = pattern
+		newLoc.Types = append([]string(nil), loc.Types...)
+		newLoc.Commands = append([]Command{}, loc.Commands...)
+		newLoc.Exclude = nil
 		result = append(result, newLoc)
 	}
 	
 	return result, nil
 }
 
+// globMatchID identifies a glob match below the id of its location: by its
+// directory name when those are unique, by its path otherwise
+func globMatchID(match, baseDir string, uniqueBases bool) string {
+	if uniqueBases {
+		return filepath.Base(match)
+	}
+	return filepath.ToSlash(relativePath(match, baseDir))
+}
+
+// uniqueBaseNames reports whether no two directories share a base name
+func uniqueBaseNames(dirs []string) bool {
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if seen[filepath.Base(dir)] {
+			return false
+		}
+		seen[filepath.Base(dir)] = true
+	}
+	return true
+}
+
+// globDirs returns the directories matching pattern, which has no braces left.
+// Wildcards never descend into node_modules, .git or directories ignored by a
+// .gitignore between baseDir and the matched directory.
+func globDirs(pattern, baseDir string) ([]string, error) {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	
+	// Start from the longest prefix without wildcards
+	root := ""
+	for len(segments) > 0 && !hasGlobMeta(segments[0]) {
+		if root == "" && segments[0] == "" {
+			root = "/"
+		} else {
+			root = filepath.Join(root, segments[0])
+		}
+		segments = segments[1:]
+	}
+	if root == "" {
+		root = "."
+	}
+	if !isDirectory(root) {
+		return nil, nil
+	}
+	
+	var matches []string
+	var walk func(dir string, ignore *ignoreMatcher, segments []string) error
+	walk = func(dir string, ignore *ignoreMatcher, segments []string) error {
+		if len(segments) == 0 {
+			matches = append(matches, dir)
+			return nil
+		}
+		
+		segment := segments[0]
+		if !hasGlobMeta(segment) {
+			next := filepath.Join(dir, segment)
+			if !isDirectory(next) {
+				return nil
+			}
+			return walk(next, ignore.enter(next), segments[1:])
+		}
+		
+		if segment == "**" {
+			// ** matches zero directories...
+			if err := walk(dir, ignore, segments[1:]); err != nil {
+				return err
+			}
+		}
+		
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to expand glob pattern %q: %w", pattern, err)
+		}
+		for _, entry := range entries {
+			child := filepath.Join(dir, entry.Name())
+			if alwaysIgnoredDirs[entry.Name()] || !isDirectory(child) || ignore.ignored(child, true) {
+				continue
+			}
+			
+			if segment == "**" {
+				// ...or any number of them
+				if err := walk(child, ignore.enter(child), segments); err != nil {
+					return err
+				}
+				continue
+			}
+			if ok, _ := path.Match(segment, entry.Name()); ok {
+				if err := walk(child, ignore.enter(child), segments[1:]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	
+	ignoreBase := baseDir
+	if ignoreBase == "" {
+		ignoreBase = root
+	}
+	if err := walk(root, newIgnoreMatcher(ignoreBase, root), segments); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// isExcluded reports whether dir, or one of its parents, matches an exclude pattern
+func isExcluded(dir string, excludes []string, baseDir string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	
+	for _, exclude := range excludes {
+		alternatives, err := expandBraces(expandPath(exclude, baseDir))
+		if err != nil {
+			continue
+		}
+		for _, alternative := range alternatives {
+			if matchPath(filepath.ToSlash(alternative)+"/**", filepath.ToSlash(absDir)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchPath matches a slash separated path against a pattern in which each
+// segment is a path.Match pattern and ** matches any number of segments
+func matchPath(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
 func isDirectory(path string) bool {
 	info, err := os.Stat(path)
 	if err != nil {
 		return false
 	}
 	return info.IsDir()
-}
\ No newline at end of file
+}
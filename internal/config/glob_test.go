@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -11,6 +12,7 @@ func TestExpandGlobPatterns(t *testing.T) {
 		name        string
 		setupDirs   []string
 		setupFiles  []string
+		gitignores  map[string]string
 		locations   []Location
 		expected    []Location
 		wantErr     bool
@@ -27,7 +29,7 @@ func TestExpandGlobPatterns(t *testing.T) {
 					Name:     "services",
 					Location: "packages/*",
 					Type:     "npm",
-					Commands: []string{"start", "build"},
+					Commands: []Command{{Run: "start"}, {Run: "build"}},
 				},
 			},
 			expected: []Location{
@@ -35,19 +37,19 @@ func TestExpandGlobPatterns(t *testing.T) {
 					Name:     "services",
 					Location: "packages/backend",
 					Type:     "npm",
-					Commands: []string{"start", "build"},
+					Commands: []Command{{Run: "start"}, {Run: "build"}},
 				},
 				{
 					Name:     "services",
 					Location: "packages/frontend",
 					Type:     "npm",
-					Commands: []string{"start", "build"},
+					Commands: []Command{{Run: "start"}, {Run: "build"}},
 				},
 				{
 					Name:     "services",
 					Location: "packages/shared",
 					Type:     "npm",
-					Commands: []string{"start", "build"},
+					Commands: []Command{{Run: "start"}, {Run: "build"}},
 				},
 			},
 			wantErr: false,
@@ -62,7 +64,7 @@ func TestExpandGlobPatterns(t *testing.T) {
 					Name:     "frontend",
 					Location: "packages/frontend",
 					Type:     "npm",
-					Commands: []string{"start"},
+					Commands: []Command{{Run: "start"}},
 				},
 			},
 			expected: []Location{
@@ -70,7 +72,7 @@ func TestExpandGlobPatterns(t *testing.T) {
 					Name:     "frontend",
 					Location: "packages/frontend",
 					Type:     "npm",
-					Commands: []string{"start"},
+					Commands: []Command{{Run: "start"}},
 				},
 			},
 			wantErr: false,
@@ -87,34 +89,38 @@ func TestExpandGlobPatterns(t *testing.T) {
 				{
 					Location: "apps/*",
 					Type:     "npm",
-					Commands: []string{"start"},
+					Commands: []Command{{Run: "start"}},
 				},
 				{
 					Location: "packages/*",
 					Type:     "npm", 
-					Commands: []string{"build"},
+					Commands: []Command{{Run: "build"}},
 				},
 			},
 			expected: []Location{
 				{
+					Name:     "mobile",
 					Location: "apps/mobile",
 					Type:     "npm",
-					Commands: []string{"start"},
+					Commands: []Command{{Run: "start"}},
 				},
 				{
+					Name:     "web",
 					Location: "apps/web",
 					Type:     "npm",
-					Commands: []string{"start"},
+					Commands: []Command{{Run: "start"}},
 				},
 				{
+					Name:     "ui",
 					Location: "packages/ui",
 					Type:     "npm",
-					Commands: []string{"build"},
+					Commands: []Command{{Run: "build"}},
 				},
 				{
+					Name:     "utils",
 					Location: "packages/utils",
 					Type:     "npm",
-					Commands: []string{"build"},
+					Commands: []Command{{Run: "build"}},
 				},
 			},
 			wantErr: false,
@@ -128,7 +134,7 @@ func TestExpandGlobPatterns(t *testing.T) {
 				{
 					Location: "services/*",
 					Type:     "go",
-					Commands: []string{"run"},
+					Commands: []Command{{Run: "run"}},
 				},
 			},
 			expected: []Location{},
@@ -148,52 +154,54 @@ func TestExpandGlobPatterns(t *testing.T) {
 				{
 					Location: "packages/*",
 					Type:     "npm",
-					Commands: []string{"test"},
+					Commands: []Command{{Run: "test"}},
 				},
 			},
 			expected: []Location{
 				{
+					Name:     "backend",
 					Location: "packages/backend",
 					Type:     "npm",
-					Commands: []string{"test"},
+					Commands: []Command{{Run: "test"}},
 				},
 				{
+					Name:     "frontend",
 					Location: "packages/frontend",
 					Type:     "npm",
-					Commands: []string{"test"},
+					Commands: []Command{{Run: "test"}},
 				},
 			},
 			wantErr: false,
 		},
 		{
-			name: "invalid glob pattern - multiple asterisks",
+			name: "invalid glob pattern - unclosed character class",
 			locations: []Location{
 				{
-					Location: "foo/*/bar/*",
+					Location: "foo/[a-/bar",
 					Type:     "npm",
-					Commands: []string{"test"},
+					Commands: []Command{{Run: "test"}},
 				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "invalid glob pattern - asterisk not at end",
+			name: "invalid glob pattern - unbalanced braces",
 			locations: []Location{
 				{
-					Location: "packages/*/src",
+					Location: "packages/{web,mobile/src",
 					Type:     "npm",
-					Commands: []string{"test"},
+					Commands: []Command{{Run: "test"}},
 				},
 			},
 			wantErr: true,
 		},
 		{
-			name: "invalid glob pattern - asterisk without slash",
+			name: "invalid glob pattern - ** inside a segment",
 			locations: []Location{
 				{
-					Location: "packages*",
+					Location: "packages**",
 					Type:     "npm",
-					Commands: []string{"test"},
+					Commands: []Command{{Run: "test"}},
 				},
 			},
 			wantErr: true,
@@ -208,19 +216,190 @@ func TestExpandGlobPatterns(t *testing.T) {
 				{
 					Location: "apps/*",
 					Type:     "npm",
-					Commands: []string{"start"},
+					Commands: []Command{{Run: "start"}},
 				},
 			},
 			expected: []Location{
 				{
+					Name:     "mobile",
 					Location: "apps/mobile",
 					Type:     "npm",
-					Commands: []string{"start"},
+					Commands: []Command{{Run: "start"}},
 				},
 				{
+					Name:     "web",
 					Location: "apps/web",
 					Type:     "npm",
-					Commands: []string{"start"},
+					Commands: []Command{{Run: "start"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "double star skips node_modules and .git",
+			setupDirs: []string{
+				"services/a/api",
+				"services/b/c/api",
+				"services/node_modules/x/api",
+				"services/.git/api",
+			},
+			locations: []Location{
+				{
+					Location: "services/**/api",
+					Type:     "go",
+					Commands: []Command{{Run: "run"}},
+				},
+			},
+			// Every match is called api, so names are left empty to show the path instead
+			expected: []Location{
+				{
+					Location: "services/a/api",
+					Type:     "go",
+					Commands: []Command{{Run: "run"}},
+				},
+				{
+					Location: "services/b/c/api",
+					Type:     "go",
+					Commands: []Command{{Run: "run"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "wildcard in the middle",
+			setupDirs: []string{
+				"services/a/api",
+				"services/b/api",
+				"services/b/web",
+			},
+			locations: []Location{
+				{
+					Name:     "apis",
+					Location: "services/*/api",
+					Type:     "go",
+					Commands: []Command{{Run: "run"}},
+				},
+			},
+			expected: []Location{
+				{
+					Name:     "apis",
+					Location: "services/a/api",
+					Type:     "go",
+					Commands: []Command{{Run: "run"}},
+				},
+				{
+					Name:     "apis",
+					Location: "services/b/api",
+					Type:     "go",
+					Commands: []Command{{Run: "run"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "braces and character classes",
+			setupDirs: []string{
+				"libs/web/ui",
+				"libs/mobile/native",
+				"libs/desktop/shell",
+				"apps/app1",
+				"apps/app2",
+				"apps/appx",
+			},
+			locations: []Location{
+				{
+					Location: "libs/{web,mobile}/*",
+					Type:     "npm",
+					Commands: []Command{{Run: "build"}},
+				},
+				{
+					Location: "apps/app[0-9]",
+					Type:     "npm",
+					Commands: []Command{{Run: "start"}},
+				},
+			},
+			expected: []Location{
+				{
+					Name:     "native",
+					Location: "libs/mobile/native",
+					Type:     "npm",
+					Commands: []Command{{Run: "build"}},
+				},
+				{
+					Name:     "ui",
+					Location: "libs/web/ui",
+					Type:     "npm",
+					Commands: []Command{{Run: "build"}},
+				},
+				{
+					Name:     "app1",
+					Location: "apps/app1",
+					Type:     "npm",
+					Commands: []Command{{Run: "start"}},
+				},
+				{
+					Name:     "app2",
+					Location: "apps/app2",
+					Type:     "npm",
+					Commands: []Command{{Run: "start"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "exclude skips matches and their subdirectories",
+			setupDirs: []string{
+				"packages/a",
+				"packages/legacy/old",
+				"packages/tmp-1",
+			},
+			locations: []Location{
+				{
+					Location: "packages/**",
+					Exclude:  []string{"packages/legacy", "packages/tmp-*"},
+					Type:     "npm",
+					Commands: []Command{{Run: "test"}},
+				},
+			},
+			expected: []Location{
+				{
+					Name:     "packages",
+					Location: "packages",
+					Type:     "npm",
+					Commands: []Command{{Run: "test"}},
+				},
+				{
+					Name:     "a",
+					Location: "packages/a",
+					Type:     "npm",
+					Commands: []Command{{Run: "test"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "gitignored directories are skipped",
+			setupDirs: []string{
+				"apps/web",
+				"apps/dist",
+				"apps/build",
+			},
+			gitignores: map[string]string{
+				"apps": "dist/\nbuild\n",
+			},
+			locations: []Location{
+				{
+					Location: "apps/*",
+					Type:     "npm",
+					Commands: []Command{{Run: "start"}},
+				},
+			},
+			expected: []Location{
+				{
+					Name:     "web",
+					Location: "apps/web",
+					Type:     "npm",
+					Commands: []Command{{Run: "start"}},
 				},
 			},
 			wantErr: false,
@@ -251,6 +430,13 @@ func TestExpandGlobPatterns(t *testing.T) {
 				}
 			}
 
+			for dir, content := range tt.gitignores {
+				err := os.WriteFile(filepath.Join(tmpDir, dir, ".gitignore"), []byte(content), 0644)
+				if err != nil {
+					t.Fatalf("Failed to create .gitignore in %s: %v", dir, err)
+				}
+			}
+
 			// Change to temp directory for glob expansion
 			oldWd, err := os.Getwd()
 			if err != nil {
@@ -291,12 +477,61 @@ func TestExpandGlobPatterns(t *testing.T) {
 						t.Errorf("Location[%d] has %d commands, expected %d", i, len(loc.Commands), len(expected.Commands))
 					}
 					for j, cmd := range loc.Commands {
-						if cmd != expected.Commands[j] {
-							t.Errorf("Location[%d].Commands[%d] = %q, expected %q", i, j, cmd, expected.Commands[j])
+						if cmd.Run != expected.Commands[j].Run {
+							t.Errorf("Location[%d].Commands[%d] = %q, expected %q", i, j, cmd.Run, expected.Commands[j].Run)
 						}
 					}
 				}
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected []string
+		wantErr  bool
+	}{
+		{pattern: "apps/*", expected: []string{"apps/*"}},
+		{pattern: "libs/{web,mobile}/*", expected: []string{"libs/web/*", "libs/mobile/*"}},
+		{pattern: "{a,b{c,d}}/x", expected: []string{"a/x", "bc/x", "bd/x"}},
+		{pattern: "{a,b", wantErr: true},
+		{pattern: "a}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			result, err := expandBraces(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandBraces(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expandBraces(%q) = %v, expected %v", tt.pattern, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"packages/*", "packages/web", true},
+		{"packages/*", "packages/web/src", false},
+		{"packages/**", "packages", true},
+		{"packages/**", "packages/web/src", true},
+		{"**/api", "services/a/api", true},
+		{"services/*/api", "services/a/b/api", false},
+		{"app[0-9]", "app7", true},
+		{"app[0-9]", "appx", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchPath(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
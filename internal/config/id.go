@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// assignLocationIDs gives every location a unique id. Ids set in the config file
+// are kept and must be unique. The others are derived from the path of the
+// location relative to the config directory ("." for the directory itself); when
+// several locations share a directory, the later ones get a "#2", "#3"... suffix.
+func assignLocationIDs(config *Config) error {
+	owners := make(map[string]*Location)
+	for i := range config.Locations {
+		location := &config.Locations[i]
+		if location.ID == "" {
+			continue
+		}
+		if owner, ok := owners[location.ID]; ok {
+			return fmt.Errorf("duplicate location id %q: %s and %s", location.ID, owner.describe(), location.describe())
+		}
+		owners[location.ID] = location
+	}
+
+	for i := range config.Locations {
+		location := &config.Locations[i]
+		if location.ID != "" {
+			continue
+		}
+
+		base := derivedID(location.Location, config.Dir)
+		id := base
+		for n := 2; owners[id] != nil; n++ {
+			id = base + "#" + strconv.Itoa(n)
+		}
+		location.ID = id
+		owners[id] = location
+	}
+	return nil
+}
+
+// derivedID is the default id of a location directory: its slash separated path
+// relative to the config directory
+func derivedID(dir, configDir string) string {
+	if configDir == "" {
+		return filepath.ToSlash(dir)
+	}
+	rel, err := filepath.Rel(configDir, dir)
+	if err != nil {
+		return filepath.ToSlash(dir)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// DuplicateNames returns the display names shared by several locations, or equal
+// to the id of another location, mapped to the ids of those locations. Such names
+// cannot address a location, its id can.
+func (c *Config) DuplicateNames() map[string][]string {
+	ids := make(map[string][]string)
+	for _, location := range c.Locations {
+		name := location.DisplayName()
+		ids[name] = append(ids[name], location.ID)
+	}
+
+	duplicates := make(map[string][]string)
+	for name, shared := range ids {
+		// The id wins when a name is another location's id
+		if other := c.FindLocationByID(name); other != nil && other.DisplayName() != name {
+			shared = append(shared, other.ID)
+		}
+		if len(shared) > 1 {
+			sort.Strings(shared)
+			duplicates[name] = shared
+		}
+	}
+	return duplicates
+}
+
+// FindLocationByID returns the location with the given id, or nil
+func (c *Config) FindLocationByID(id string) *Location {
+	if id == "" {
+		return nil
+	}
+	for i := range c.Locations {
+		if c.Locations[i].ID == id {
+			return &c.Locations[i]
+		}
+	}
+	return nil
+}
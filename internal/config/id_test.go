@@ -0,0 +1,120 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigLocationIDs(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".gopmrc": `locations:
+  - name: api
+    location: services/api
+    type: none
+    commands: [make run]
+  - name: api-tools
+    id: tools
+    location: services/api
+    type: none
+    commands: [make lint]
+  - name: jobs
+    location: "jobs/*/*"
+    type: none
+    commands: [./run.sh]
+  - id: apps
+    location: "apps/*"
+    type: none
+    commands: [npm start]
+  - location: services/api
+    type: none
+    commands: [ls]
+`,
+		"jobs/daily/report/run.sh":  "",
+		"jobs/weekly/report/run.sh": "",
+		"apps/web/package.json":     "{}",
+		"apps/admin/package.json":   "{}",
+	})
+
+	config, err := LoadConfig(filepath.Join(tmpDir, ".gopmrc"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	var ids []string
+	for _, location := range config.Locations {
+		ids = append(ids, location.ID)
+	}
+	expected := []string{"services/api", "tools", "jobs/daily/report", "jobs/weekly/report", "apps/admin", "apps/web", "services/api#2"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("ids = %v, expected %v", ids, expected)
+	}
+
+	duplicates := config.DuplicateNames()
+	expectedDuplicates := map[string][]string{
+		"jobs": {"jobs/daily/report", "jobs/weekly/report"},
+		// The path of the last location is the id of the first one
+		"services/api": {"services/api", "services/api#2"},
+	}
+	if !reflect.DeepEqual(duplicates, expectedDuplicates) {
+		t.Errorf("DuplicateNames() = %v, expected %v", duplicates, expectedDuplicates)
+	}
+
+	if location := config.FindLocationByID("apps/web"); location == nil || location.Name != "web" {
+		t.Errorf("FindLocationByID(apps/web) = %+v", location)
+	}
+	if location := config.FindLocationByID(""); location != nil {
+		t.Errorf("FindLocationByID(\"\") = %+v, expected nil", location)
+	}
+}
+
+func TestDuplicateNamesMatchingAnotherID(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".gopmrc": `locations:
+  - name: frontend
+    location: web
+    type: none
+    commands: [echo WEB]
+  - name: ui
+    location: frontend
+    type: none
+    commands: [echo UI]
+`,
+	})
+
+	config, err := LoadConfig(filepath.Join(tmpDir, ".gopmrc"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	duplicates := config.DuplicateNames()
+	expected := map[string][]string{"frontend": {"frontend", "web"}}
+	if !reflect.DeepEqual(duplicates, expected) {
+		t.Errorf("DuplicateNames() = %v, expected %v", duplicates, expected)
+	}
+	if len(config.Warnings) != 1 || !strings.Contains(config.Warnings[0].Message, `location name "frontend"`) {
+		t.Errorf("Warnings = %+v, expected one about the name frontend", config.Warnings)
+	}
+}
+
+func TestLoadConfigDuplicateLocationIDs(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".gopmrc": `locations:
+  - id: app
+    location: a
+    type: none
+  - id: app
+    location: b
+    type: none
+`,
+	})
+
+	_, err := LoadConfig(filepath.Join(tmpDir, ".gopmrc"))
+	if err == nil || !strings.Contains(err.Error(), `duplicate location id "app"`) {
+		t.Fatalf("Expected a duplicate id error, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// alwaysIgnoredDirs are never entered when expanding wildcards
+var alwaysIgnoredDirs = map[string]bool{
+	"node_modules": true,
+	".git":         true,
+}
+
+// ignoreRule is a single pattern from a .gitignore file
+type ignoreRule struct {
+	pattern  string
+	base     string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ignoreMatcher decides whether a path is excluded by .gitignore files.
+// Rules of nested .gitignore files apply to their own directory only, and the
+// last matching rule wins.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// newIgnoreMatcher loads the .gitignore files from baseDir down to dir. When dir
+// is not inside baseDir only the .gitignore of dir itself is loaded.
+func newIgnoreMatcher(baseDir, dir string) *ignoreMatcher {
+	matcher := &ignoreMatcher{}
+
+	rel := relativePath(dir, baseDir)
+	if baseDir == "" || filepath.IsAbs(rel) {
+		return matcher.enter(dir)
+	}
+
+	current := baseDir
+	matcher = matcher.enter(current)
+	if rel == "." {
+		return matcher
+	}
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		matcher = matcher.enter(current)
+	}
+	return matcher
+}
+
+// enter returns the matcher for dir, adding the rules of dir/.gitignore if present
+func (m *ignoreMatcher) enter(dir string) *ignoreMatcher {
+	file, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return m
+	}
+	defer file.Close()
+
+	rules := append([]ignoreRule(nil), m.rules...)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text(), dir); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return &ignoreMatcher{rules: rules}
+}
+
+// parseIgnoreRule parses a .gitignore line, skipping blank lines and comments
+func parseIgnoreRule(line, base string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// A slash anywhere but at the end anchors the pattern at the .gitignore directory
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	rule.pattern = line
+	return rule, true
+}
+
+// ignored reports whether path is excluded by the loaded rules
+func (m *ignoreMatcher) ignored(path string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel, err := filepath.Rel(rule.base, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+
+		var matched bool
+		if rule.anchored {
+			matched = matchPath(rule.pattern, rel)
+		} else {
+			matched = matchPath(rule.pattern, rel[strings.LastIndex(rel, "/")+1:])
+		}
+		if matched {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
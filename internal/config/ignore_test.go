@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	gitignores := map[string]string{
+		".gitignore":          "# build output\ndist/\n/generated\n*.tmp\nvendor\n!vendor/keep\n",
+		"apps/.gitignore":     "coverage\n",
+		"apps/web/.gitignore": "!dist\n",
+	}
+	for file, content := range gitignores {
+		path := filepath.Join(tmpDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		dir   string
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{name: "directory pattern", dir: ".", path: "dist", isDir: true, want: true},
+		{name: "directory pattern skips files", dir: ".", path: "dist", isDir: false, want: false},
+		{name: "basename pattern in subdirectory", dir: "apps", path: "apps/dist", isDir: true, want: true},
+		{name: "anchored pattern at root", dir: ".", path: "generated", isDir: true, want: true},
+		{name: "anchored pattern not in subdirectory", dir: "apps", path: "apps/generated", isDir: true, want: false},
+		{name: "wildcard", dir: ".", path: "cache.tmp", isDir: true, want: true},
+		{name: "negation", dir: "vendor", path: "vendor/keep", isDir: true, want: false},
+		{name: "nested .gitignore", dir: "apps", path: "apps/coverage", isDir: true, want: true},
+		{name: "nested .gitignore scoped to its directory", dir: ".", path: "coverage", isDir: true, want: false},
+		{name: "nested negation wins", dir: "apps/web", path: "apps/web/dist", isDir: true, want: false},
+		{name: "unmatched", dir: "apps", path: "apps/web", isDir: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := newIgnoreMatcher(tmpDir, filepath.Join(tmpDir, tt.dir))
+			got := matcher.ignored(filepath.Join(tmpDir, tt.path), tt.isDir)
+			if got != tt.want {
+				t.Errorf("ignored(%s) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// loadWithOverlay loads a config file and merges the .gopmrc.local.yaml overlay
+// next to it on top
+func loadWithOverlay(configPath string) (*Config, error) {
+	config, err := loadConfigFile(configPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	localPath := filepath.Join(filepath.Dir(configPath), LocalConfigFileName)
+	if localPath == configPath {
+		// Only the overlay exists, everything in it is personal
+		config.LocalPath = localPath
+		return config, nil
+	}
+	if _, err := os.Stat(localPath); err != nil {
+		return config, nil
+	}
+
+	local, err := loadConfigFile(localPath, []string{configPath})
+	if err != nil {
+		return nil, err
+	}
+	config = mergeConfigs(config, local)
+	config.LocalPath = localPath
+	return config, nil
+}
+
+// filterLocations applies the only and hide lists of the config. Only keeps the
+// locations matching one of its patterns, then hide drops matching locations.
+func filterLocations(config *Config) error {
+	for _, pattern := range append(append([]string(nil), config.Only...), config.Hide...) {
+		if err := validateGlobPattern(pattern); err != nil {
+			return fmt.Errorf("invalid only/hide pattern: %w", err)
+		}
+	}
+	if len(config.Only) == 0 && len(config.Hide) == 0 {
+		return nil
+	}
+
+	var kept []Location
+	for _, location := range config.Locations {
+		if len(config.Only) > 0 && !location.MatchesPattern(config.Only) {
+			continue
+		}
+		if location.MatchesPattern(config.Hide) {
+			continue
+		}
+		kept = append(kept, location)
+	}
+	config.Locations = kept
+	return nil
+}
+
+// MatchesPattern reports whether one of the glob patterns matches the name of the
+// location, its path or a parent directory of its path (e.g. "services" matches
+// the location at services/api)
+func (l *Location) MatchesPattern(patterns []string) bool {
+	path := filepath.ToSlash(l.Path)
+	for _, pattern := range patterns {
+		alternatives, err := expandBraces(pattern)
+		if err != nil {
+			continue
+		}
+		for _, alternative := range alternatives {
+			if l.Name != "" && matchPath(alternative, l.Name) {
+				return true
+			}
+			if path != "" && matchPath(filepath.ToSlash(filepath.Clean(alternative))+"/**", path) {
+				return true
+			}
+		}
+	}
+	return false
+}
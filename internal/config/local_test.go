@@ -0,0 +1,136 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigLocalOverlay(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".gopmrc": `locations:
+  - name: api
+    location: services/api
+    type: none
+    commands:
+      - name: serve
+        run: go run ./cmd/api
+        env:
+          PORT: "8080"
+          LOG_LEVEL: info
+  - name: web
+    location: apps/web
+    type: none
+    commands:
+      - npm start
+  - name: docs
+    location: docs
+    type: none
+    commands:
+      - mkdocs serve
+`,
+		LocalConfigFileName: `only: [api, "apps/*", scratch]
+locations:
+  - name: api
+    commands:
+      - name: serve
+        run: go run ./cmd/api
+        env:
+          PORT: "9090"
+      - ./scripts/seed-my-db.sh
+  - name: scratch
+    location: ../scratch
+    type: none
+    commands:
+      - ls
+`,
+	})
+
+	configPath := filepath.Join(tmpDir, ".gopmrc")
+	localPath := filepath.Join(tmpDir, LocalConfigFileName)
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if config.LocalPath != localPath {
+		t.Errorf("LocalPath = %q, expected %q", config.LocalPath, localPath)
+	}
+
+	var names []string
+	for _, location := range config.Locations {
+		names = append(names, location.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"api", "web", "scratch"}) {
+		t.Fatalf("Expected only api, web and scratch, got %v", names)
+	}
+
+	expected := []Command{
+		{Name: "serve", Run: "go run ./cmd/api", Env: map[string]string{"PORT": "9090", "LOG_LEVEL": "info"}, Origin: localPath},
+		{Run: "./scripts/seed-my-db.sh", Origin: localPath},
+	}
+	if !reflect.DeepEqual(config.Locations[0].Commands, expected) {
+		t.Errorf("api commands = %+v, expected %+v", config.Locations[0].Commands, expected)
+	}
+
+	// Touching api in the overlay does not make the overlay its origin
+	if config.Locations[0].Source != configPath {
+		t.Errorf("Expected api to keep coming from the config file, got %s", config.Locations[0].Source)
+	}
+	if config.Locations[2].Source != localPath {
+		t.Errorf("Expected scratch to come from the overlay, got %s", config.Locations[2].Source)
+	}
+	if config.Locations[1].Commands[0].Origin != configPath {
+		t.Errorf("Expected web commands to come from the config file")
+	}
+}
+
+func TestLoadConfigHide(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".gopmrc": `locations:
+  - location: "packages/*"
+    type: none
+    commands:
+      - make
+`,
+		LocalConfigFileName:            "hide: [legacy-*]\n",
+		"packages/app/README.md":       "",
+		"packages/legacy-ui/README.md": "",
+	})
+
+	config, err := LoadConfig(filepath.Join(tmpDir, ".gopmrc"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(config.Locations) != 1 || config.Locations[0].Name != "app" {
+		t.Errorf("Expected only the app location, got %+v", config.Locations)
+	}
+}
+
+func TestLocationMatchesPattern(t *testing.T) {
+	location := Location{Name: "api", Path: "services/api"}
+
+	tests := []struct {
+		patterns []string
+		want     bool
+	}{
+		{[]string{"api"}, true},
+		{[]string{"a*"}, true},
+		{[]string{"services"}, true},
+		{[]string{"services/api"}, true},
+		{[]string{"services/*"}, true},
+		{[]string{"./services"}, true},
+		{[]string{"{web,api}"}, true},
+		{[]string{"web", "docs"}, false},
+		{[]string{"serv"}, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := location.MatchesPattern(tt.patterns); got != tt.want {
+			t.Errorf("MatchesPattern(%v) = %v, want %v", tt.patterns, got, tt.want)
+		}
+	}
+}
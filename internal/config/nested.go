@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// mergeParentConfigs merges the .gopmrc files of the parent directories of
+// configPath into config. The search stops at a file with root: true, or at the
+// filesystem root. Files are merged from the outermost down, so settings of the
+// nearer file win, and the locations of every file but the outermost are marked
+// with their project name, see prefixProjectNames.
+func mergeParentConfigs(config *Config, configPath string) (*Config, error) {
+	layers := []*Config{config}
+
+	dir := filepath.Dir(configPath)
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+
+		parentPath := filepath.Join(dir, ConfigFileName)
+		if _, err := os.Stat(parentPath); err != nil {
+			continue
+		}
+		parentConfig, err := loadWithOverlay(parentPath)
+		if err != nil {
+			return nil, err
+		}
+		layers = append([]*Config{parentConfig}, layers...)
+
+		if parentConfig.Root {
+			break
+		}
+	}
+
+	merged := layers[0]
+	for _, layer := range layers[1:] {
+		project := layer.Name
+		if project == "" {
+			project = filepath.Base(layer.Dir)
+		}
+		for i := range layer.Locations {
+			layer.Locations[i].Project = project
+		}
+		merged = mergeConfigs(merged, layer)
+	}
+
+	// The personal overlay that counts is the one of the nearest file
+	merged.LocalPath = config.LocalPath
+	return merged, nil
+}
+
+// prefixProjectNames prefixes the names and explicit ids of locations from nested
+// projects with the project name, as "project/name". Unnamed locations use their
+// path relative to the config file that declared them.
+func prefixProjectNames(config *Config) {
+	for i := range config.Locations {
+		location := &config.Locations[i]
+		if location.Project == "" {
+			continue
+		}
+
+		name := location.Name
+		if name == "" {
+			name = filepath.ToSlash(relativePath(location.Location, filepath.Dir(location.Source)))
+		}
+		location.Name = location.Project + "/" + name
+		if location.ID != "" {
+			location.ID = location.Project + "/" + location.ID
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigMergeParents(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		// Above the root marker, never merged
+		".gopmrc": `locations:
+  - name: outside
+    location: .
+    type: none
+    commands: [ls]
+`,
+		"mono/.gopmrc": `root: true
+shell: /bin/bash
+locations:
+  - name: tools
+    location: tools
+    type: none
+    commands: [make lint]
+  - name: api
+    location: services/api
+    type: none
+    commands: [make run]
+`,
+		"mono/payments/.gopmrc": `merge_parents: true
+shell: /bin/zsh
+locations:
+  - name: api
+    location: api
+    type: none
+    commands: [go run .]
+  - location: worker
+    type: none
+    commands: [go run ./worker]
+`,
+		"mono/payments/billing/.gopmrc": `merge_parents: true
+name: billing
+locations:
+  - location: "jobs/*"
+    type: none
+    commands: [./run.sh]
+`,
+		"mono/payments/billing/jobs/invoices/run.sh": "",
+	})
+
+	tests := []struct {
+		name      string
+		config    string
+		shell     string
+		locations map[string]string
+	}{
+		{
+			name:   "one level",
+			config: "mono/payments/.gopmrc",
+			shell:  "/bin/zsh",
+			locations: map[string]string{
+				"tools":           "mono/tools",
+				"api":             "mono/services/api",
+				"payments/api":    "mono/payments/api",
+				"payments/worker": "mono/payments/worker",
+			},
+		},
+		{
+			name:   "two levels",
+			config: "mono/payments/billing/.gopmrc",
+			shell:  "/bin/zsh",
+			locations: map[string]string{
+				"tools":            "mono/tools",
+				"api":              "mono/services/api",
+				"payments/api":     "mono/payments/api",
+				"payments/worker":  "mono/payments/worker",
+				"billing/invoices": "mono/payments/billing/jobs/invoices",
+			},
+		},
+		{
+			name:   "root file alone",
+			config: "mono/.gopmrc",
+			shell:  "/bin/bash",
+			locations: map[string]string{
+				"tools": "mono/tools",
+				"api":   "mono/services/api",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := LoadConfig(filepath.Join(tmpDir, tt.config))
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			if config.Shell != tt.shell {
+				t.Errorf("Shell = %q, expected %q", config.Shell, tt.shell)
+			}
+
+			locations := make(map[string]string)
+			for _, location := range config.Locations {
+				rel, err := filepath.Rel(tmpDir, location.Location)
+				if err != nil {
+					t.Fatal(err)
+				}
+				locations[location.Name] = filepath.ToSlash(rel)
+			}
+			if !reflect.DeepEqual(locations, tt.locations) {
+				t.Errorf("Locations = %v, expected %v", locations, tt.locations)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMergeParentsWithIncludes(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		// Above the root marker, never merged
+		".gopmrc": `locations:
+  - name: outside
+    location: .
+    type: none
+    commands: [ls]
+`,
+		"mono/.gopmrc": `root: true
+include: [tools.yaml]
+locations:
+  - name: api
+    location: services/api
+    type: none
+    commands: [make run]
+`,
+		"mono/tools.yaml": `locations:
+  - name: tools
+    location: tools
+    type: none
+    commands: [make lint]
+`,
+		"mono/payments/.gopmrc": `merge_parents: true
+name: pay
+extends: [base.yaml]
+locations:
+  - name: api
+    location: api
+    type: none
+    commands: [go run .]
+`,
+		// The name, root and merge_parents of a layer do not apply to the file using it
+		"mono/payments/base.yaml": `root: true
+merge_parents: false
+name: base
+locations:
+  - name: worker
+    location: worker
+    type: none
+    commands: [go run ./worker]
+`,
+	})
+
+	config, err := LoadConfig(filepath.Join(tmpDir, "mono", "payments", ".gopmrc"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	locations := make(map[string]string)
+	for _, location := range config.Locations {
+		rel, err := filepath.Rel(tmpDir, location.Location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locations[location.Name] = filepath.ToSlash(rel)
+	}
+	expected := map[string]string{
+		"tools":      "mono/tools",
+		"api":        "mono/services/api",
+		"pay/api":    "mono/payments/api",
+		"pay/worker": "mono/payments/worker",
+	}
+	if !reflect.DeepEqual(locations, expected) {
+		t.Errorf("Locations = %v, expected %v", locations, expected)
+	}
+}
+
+func TestLoadConfigWithoutMergeParents(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".gopmrc": `locations:
+  - name: root
+    location: .
+    type: none
+    commands: [ls]
+`,
+		"sub/.gopmrc": `locations:
+  - name: api
+    location: .
+    type: none
+    commands: [ls]
+`,
+	})
+
+	config, err := LoadConfig(filepath.Join(tmpDir, "sub", ".gopmrc"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(config.Locations) != 1 || config.Locations[0].Name != "api" {
+		t.Errorf("Expected only the api location, got %+v", config.Locations)
+	}
+}
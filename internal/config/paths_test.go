@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GOPM_TEST_ROOT", "/srv/projects")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		baseDir  string
+		expected string
+	}{
+		{
+			name:     "relative to base directory",
+			path:     "packages/web",
+			baseDir:  "/repo",
+			expected: "/repo/packages/web",
+		},
+		{
+			name:     "parent of base directory",
+			path:     "../shared",
+			baseDir:  "/repo/app",
+			expected: "/repo/shared",
+		},
+		{
+			name:     "absolute path",
+			path:     "/opt/tools",
+			baseDir:  "/repo",
+			expected: "/opt/tools",
+		},
+		{
+			name:     "home directory",
+			path:     "~/src/api",
+			baseDir:  "/repo",
+			expected: filepath.Join(home, "src", "api"),
+		},
+		{
+			name:     "environment variable",
+			path:     "$GOPM_TEST_ROOT/api",
+			baseDir:  "/repo",
+			expected: "/srv/projects/api",
+		},
+		{
+			name:     "braced environment variable",
+			path:     "${GOPM_TEST_ROOT}/web",
+			baseDir:  "/repo",
+			expected: "/srv/projects/web",
+		},
+		{
+			name:     "relative without base directory",
+			path:     "web",
+			expected: filepath.Join(cwd, "web"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandPath(tt.path, tt.baseDir); got != tt.expected {
+				t.Errorf("expandPath(%q, %q) = %q, expected %q", tt.path, tt.baseDir, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadConfigAnchorsLocations(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	root := t.TempDir()
+	for _, dir := range []string{"packages/web", "packages/api", "elsewhere"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+
+	configYAML := `locations:
+  - location: "packages/*"
+    type: none
+  - location: "scripts"
+    type: none
+  - name: "dotfiles"
+    location: "~/dotfiles"
+    type: none`
+	configPath := filepath.Join(root, ".gopmrc")
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	// Load from an unrelated working directory
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	defer os.Chdir(oldWd)
+	elsewhere := filepath.Join(root, "elsewhere")
+	if err := os.Chdir(elsewhere); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	expected := []struct {
+		location    string
+		displayName string
+	}{
+		{filepath.Join(root, "packages", "api"), "api"},
+		{filepath.Join(root, "packages", "web"), "web"},
+		{filepath.Join(root, "scripts"), "scripts"},
+		{filepath.Join(home, "dotfiles"), "dotfiles"},
+	}
+	if len(config.Locations) != len(expected) {
+		t.Fatalf("Expected %d locations, got %d", len(expected), len(config.Locations))
+	}
+	for i, exp := range expected {
+		loc := config.Locations[i]
+		if loc.Location != exp.location {
+			t.Errorf("Location[%d].Location = %q, expected %q", i, loc.Location, exp.location)
+		}
+		if loc.DisplayName() != exp.displayName {
+			t.Errorf("Location[%d].DisplayName() = %q, expected %q", i, loc.DisplayName(), exp.displayName)
+		}
+	}
+
+	if cwd, _ := os.Getwd(); cwd != elsewhere {
+		t.Errorf("LoadConfig() changed the working directory to %q", cwd)
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/martin/go-pm/internal/history"
+)
+
+// Values of the sort option
+const (
+	SortConfig   = "config"   // Declared commands, then base commands, then parsed commands in source order
+	SortAlpha    = "alpha"    // Alphabetically by the text shown for the command
+	SortFrecency = "frecency" // Most frequently and recently run commands first
+)
+
+// sortCommands orders the commands of each location by its sort option, or the config's
+func sortCommands(config *Config) error {
+	var commandHistory *history.History
+	now := time.Now()
+
+	for i := range config.Locations {
+		location := &config.Locations[i]
+
+		mode := location.Sort
+		if mode == "" {
+			mode = config.Sort
+		}
+
+		switch mode {
+		case "", SortConfig:
+			// Commands are already in config order
+		case SortAlpha:
+			sort.SliceStable(location.Commands, func(a, b int) bool {
+				return strings.ToLower(location.Commands[a].Title()) < strings.ToLower(location.Commands[b].Title())
+			})
+		case SortFrecency:
+			if commandHistory == nil {
+				loaded, err := history.LoadDefault()
+				if err != nil {
+					// Without a readable history every command scores the same
+					loaded = &history.History{}
+				}
+				commandHistory = loaded
+			}
+
+			scores := make([]float64, len(location.Commands))
+			for j, cmd := range location.Commands {
+				scores[j] = commandHistory.Frecency(config.ResolvePath(location.CommandDirectory(cmd)), cmd.Run, now)
+			}
+			sortByScore(location.Commands, scores)
+		default:
+			return fmt.Errorf("location %s has invalid sort %q (expected %s, %s or %s)",
+				location.describe(), mode, SortConfig, SortAlpha, SortFrecency)
+		}
+	}
+
+	return nil
+}
+
+// sortByScore orders commands by descending score, keeping the order of equal scores
+func sortByScore(commands []Command, scores []float64) {
+	indices := make([]int, len(commands))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(a, b int) bool {
+		return scores[indices[a]] > scores[indices[b]]
+	})
+
+	sorted := make([]Command, len(commands))
+	for i, index := range indices {
+		sorted[i] = commands[index]
+	}
+	copy(commands, sorted)
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/martin/go-pm/internal/history"
+)
+
+func commandTitles(commands []Command) string {
+	var titles []string
+	for _, cmd := range commands {
+		titles = append(titles, cmd.Title())
+	}
+	return strings.Join(titles, ", ")
+}
+
+func TestSortCommands(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := t.TempDir()
+	commandHistory, err := history.Load(filepath.Join(home, ".gopm", "history.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	now := time.Now()
+	commandHistory.Record(filepath.Join(dir, "web"), "npm run lint", now)
+	commandHistory.Record(filepath.Join(dir, "web"), "npm run lint", now)
+	commandHistory.Record(filepath.Join(dir, "web", "docs"), "npm run serve", now)
+	if err := commandHistory.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	commands := func() []Command {
+		return []Command{
+			{Run: "npm run test"},
+			{Name: "Build", Run: "npm run build"},
+			{Run: "npm run serve", Cwd: "docs"},
+			{Run: "npm run lint"},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		configSort  string
+		sort        string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "config order by default",
+			expected: "npm run test, Build, npm run serve, npm run lint",
+		},
+		{
+			name:     "alphabetical",
+			sort:     SortAlpha,
+			expected: "Build, npm run lint, npm run serve, npm run test",
+		},
+		{
+			name:       "global sort applies to locations",
+			configSort: SortAlpha,
+			expected:   "Build, npm run lint, npm run serve, npm run test",
+		},
+		{
+			name:       "location sort overrides global sort",
+			configSort: SortAlpha,
+			sort:       SortConfig,
+			expected:   "npm run test, Build, npm run serve, npm run lint",
+		},
+		{
+			name:     "frecency keeps config order for unused commands",
+			sort:     SortFrecency,
+			expected: "npm run lint, npm run serve, npm run test, Build",
+		},
+		{
+			name:        "invalid sort",
+			sort:        "random",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Dir:  dir,
+				Sort: tt.configSort,
+				Locations: []Location{
+					{Location: "web", Sort: tt.sort, Commands: commands()},
+				},
+			}
+
+			err := sortCommands(config)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("sortCommands() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+			if got := commandTitles(config.Locations[0].Commands); got != tt.expected {
+				t.Errorf("sortCommands() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
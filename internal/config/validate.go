@@ -0,0 +1,490 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/martin/go-pm/internal/projecttypes"
+	"gopkg.in/yaml.v3"
+)
+
+// Severities of validation problems
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem is an issue found by Validate. Line and Column point into File, they
+// are 0 for problems without a position, e.g. locations found by discover.
+type Problem struct {
+	File     string
+	Line     int
+	Column   int
+	Severity string
+	Message  string
+}
+
+// String formats the problem as "file:line:column: severity: message"
+func (p Problem) String() string {
+	position := p.File
+	if p.Line > 0 {
+		position = fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s: %s: %s", position, p.Severity, p.Message)
+}
+
+// HasErrors reports whether one of the problems is an error rather than a warning
+func HasErrors(problems []Problem) bool {
+	for _, problem := range problems {
+		if problem.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys allowed in the mappings of a config file. yaml.v3 does not pass
+// KnownFields on to custom UnmarshalYAML methods, so the node tree is checked
+// against the yaml tags instead, plus the keys the UnmarshalYAML methods decode.
+var (
+	configKeys   = yamlKeys(Config{}, "workspaces", "extends")
+	locationKeys = yamlKeys(Location{}, "type")
+	commandKeys  = yamlKeys(Command{})
+	discoverKeys = yamlKeys(Discover{})
+)
+
+// Validate checks a config file, the files it is composed of and the loaded config.
+//
+// Errors are problems that break loading or running commands: unknown keys,
+// values of the wrong type, unknown project types, missing directories and any
+// other load error. Warnings point at likely mistakes: location names shared by
+// several locations, failing parsers, directories matched by more than one glob
+// pattern and locations without commands.
+func Validate(configPath string) ([]Problem, error) {
+	configPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config directory: %w", err)
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	v := &validator{files: make(map[string]*yaml.Node)}
+	root := v.checkFile(configPath)
+	v.checkOverlay(configPath)
+
+	var flags struct {
+		MergeParents bool `yaml:"merge_parents"`
+		Root         bool `yaml:"root"`
+	}
+	if root != nil {
+		root.Decode(&flags)
+	}
+	if flags.MergeParents && !flags.Root {
+		v.checkParents(configPath)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		// Load errors usually repeat a problem found with its position
+		if !HasErrors(v.problems) {
+			v.add(configPath, nil, SeverityError, err.Error())
+		}
+	} else {
+		v.lint(config, configPath)
+	}
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		a, b := v.problems[i], v.problems[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return v.problems, nil
+}
+
+// validator collects the problems of the files of a config
+type validator struct {
+	// files maps the checked files to their root node, nil when unreadable or empty
+	files    map[string]*yaml.Node
+	problems []Problem
+}
+
+// add records a problem at the position of node, or at the file when node is nil
+func (v *validator) add(file string, node *yaml.Node, severity, message string) {
+	problem := Problem{File: file, Severity: severity, Message: message}
+	if node != nil {
+		problem.Line = node.Line
+		problem.Column = node.Column
+	}
+	v.problems = append(v.problems, problem)
+}
+
+// checkFile checks a config file and the files it extends and includes, and
+// returns its root node
+func (v *validator) checkFile(path string) *yaml.Node {
+	if root, ok := v.files[path]; ok {
+		return root
+	}
+	v.files[path] = nil
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		v.add(path, nil, SeverityError, err.Error())
+		return nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		v.addDecodeErrors(path, nil, err)
+		return nil
+	}
+	if len(document.Content) == 0 {
+		return nil
+	}
+	root := document.Content[0]
+	v.files[path] = root
+
+	// Decode the file like LoadConfig does to report values of the wrong type
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var config Config
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		v.addDecodeErrors(path, root, err)
+	}
+
+	v.checkKeys(path, root, configKeys, "config")
+	if discover := mappingValue(root, "discover"); discover != nil {
+		v.checkKeys(path, discover, discoverKeys, "discover")
+	}
+	v.checkLocations(path, root)
+	v.checkReferences(path, root)
+	return root
+}
+
+// checkOverlay checks the personal overlay next to a config file
+func (v *validator) checkOverlay(configPath string) {
+	localPath := filepath.Join(filepath.Dir(configPath), LocalConfigFileName)
+	if localPath != configPath && fileExists(localPath) {
+		v.checkFile(localPath)
+	}
+}
+
+// checkParents checks the parent config files merged by merge_parents, see mergeParentConfigs
+func (v *validator) checkParents(configPath string) {
+	dir := filepath.Dir(configPath)
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return
+		}
+		dir = parent
+
+		parentPath := filepath.Join(dir, ConfigFileName)
+		if !fileExists(parentPath) {
+			continue
+		}
+		root := v.checkFile(parentPath)
+		v.checkOverlay(parentPath)
+
+		var flags struct {
+			Root bool `yaml:"root"`
+		}
+		if root != nil {
+			root.Decode(&flags)
+		}
+		if flags.Root {
+			return
+		}
+	}
+}
+
+// decodeLine matches the position yaml.v3 prefixes its error messages with
+var decodeLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// addDecodeErrors records the errors of decoding a file, at the line yaml.v3
+// reports. The column is that of the first node on the line.
+func (v *validator) addDecodeErrors(path string, root *yaml.Node, err error) {
+	messages := []string{err.Error()}
+	var typeError *yaml.TypeError
+	if errors.As(err, &typeError) {
+		messages = typeError.Errors
+	}
+
+	for _, message := range messages {
+		problem := Problem{File: path, Severity: SeverityError, Message: strings.TrimPrefix(message, "yaml: ")}
+		if match := decodeLine.FindStringSubmatch(message); match != nil {
+			problem.Line, _ = strconv.Atoi(match[1])
+			problem.Column = firstColumn(root, problem.Line)
+			problem.Message = match[2]
+		}
+		v.problems = append(v.problems, problem)
+	}
+}
+
+// checkKeys reports the keys of a mapping that are not in keys
+func (v *validator) checkKeys(path string, node *yaml.Node, keys map[string]bool, what string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if keys[key.Value] {
+			continue
+		}
+		message := fmt.Sprintf("unknown key %q in %s", key.Value, what)
+		if suggestion := closestKey(key.Value, keys); suggestion != "" {
+			message += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		v.add(path, key, SeverityError, message)
+	}
+}
+
+// checkLocations checks the keys, project types and directories of the locations of a file
+func (v *validator) checkLocations(path string, root *yaml.Node) {
+	locations := mappingValue(root, "locations")
+	if locations == nil || locations.Kind != yaml.SequenceNode {
+		return
+	}
+
+	for _, item := range locations.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		v.checkKeys(path, item, locationKeys, "location")
+
+		if commands := mappingValue(item, "commands"); commands != nil && commands.Kind == yaml.SequenceNode {
+			for _, command := range commands.Content {
+				v.checkKeys(path, command, commandKeys, "command")
+			}
+		}
+
+		if typeNode := mappingValue(item, "type"); typeNode != nil {
+			v.checkTypes(path, typeNode)
+		}
+
+		// Locations without a directory override a location declared elsewhere
+		directory := mappingValue(item, "location")
+		if directory != nil && directory.Kind == yaml.ScalarNode && !hasGlobMeta(directory.Value) {
+			if !isDirectory(expandPath(directory.Value, filepath.Dir(path))) {
+				v.add(path, directory, SeverityError, fmt.Sprintf("directory %s does not exist", directory.Value))
+			}
+		}
+	}
+}
+
+// checkTypes reports unknown project types in the type field of a location
+func (v *validator) checkTypes(path string, node *yaml.Node) {
+	names := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		names = node.Content
+	}
+
+	for _, name := range names {
+		if name.Kind != yaml.ScalarNode || name.Value == TypeNone {
+			continue
+		}
+		if _, err := projecttypes.GetProjectType(name.Value); err != nil {
+			message := fmt.Sprintf("unknown project type %q", name.Value)
+			if available, err := projecttypes.ListAvailableTypes(); err == nil {
+				sort.Strings(available)
+				message += fmt.Sprintf(", available types: %s", strings.Join(append(available, TypeNone), ", "))
+			}
+			v.add(path, name, SeverityError, message)
+		}
+	}
+}
+
+// checkReferences checks the files a config file extends and includes
+func (v *validator) checkReferences(path string, root *yaml.Node) {
+	dir := filepath.Dir(path)
+
+	for _, base := range scalars(mappingValue(root, "extends")) {
+		basePath := expandPath(base.Value, dir)
+		if !fileExists(basePath) {
+			v.add(path, base, SeverityError, fmt.Sprintf("extended config %s does not exist", base.Value))
+			continue
+		}
+		v.checkFile(basePath)
+	}
+
+	for _, include := range scalars(mappingValue(root, "include")) {
+		files, err := resolveIncludes([]string{include.Value}, dir)
+		if err != nil {
+			v.add(path, include, SeverityError, err.Error())
+			continue
+		}
+		for _, file := range files {
+			v.checkFile(file)
+		}
+	}
+}
+
+// lint reports likely mistakes in a loaded config
+func (v *validator) lint(config *Config, configPath string) {
+	position := func(location *Location) (string, *yaml.Node) {
+		if location.Source == "" || location.Line == 0 {
+			return configPath, nil
+		}
+		return location.Source, &yaml.Node{Line: location.Line, Column: location.Column}
+	}
+
+	// Duplicate names and failing parsers
+	for _, warning := range config.Warnings {
+		problem := Problem{File: configPath, Severity: SeverityWarning, Message: warning.Message}
+		if warning.Source != "" && warning.Line > 0 {
+			problem.File, problem.Line, problem.Column = warning.Source, warning.Line, warning.Column
+		}
+		v.problems = append(v.problems, problem)
+	}
+
+	// Directories matched by a glob and another declaration
+	byDirectory := make(map[string][]*Location)
+	for i := range config.Locations {
+		location := &config.Locations[i]
+		for _, other := range byDirectory[location.Location] {
+			if other.Source == location.Source && other.Line == location.Line {
+				continue
+			}
+			if location.Pattern == "" && other.Pattern == "" {
+				continue
+			}
+			file, node := position(location)
+			v.add(file, node, SeverityWarning, fmt.Sprintf("%s overlaps %s (%s:%d): both match %s",
+				declaration(location), declaration(other), relativePath(other.Source, config.Dir), other.Line,
+				relativePath(location.Location, config.Dir)))
+			break
+		}
+		byDirectory[location.Location] = append(byDirectory[location.Location], location)
+	}
+
+	for i := range config.Locations {
+		location := &config.Locations[i]
+		if len(location.Commands) == 0 {
+			file, node := position(location)
+			v.add(file, node, SeverityWarning, fmt.Sprintf("location %s has no commands", location.DisplayName()))
+		}
+	}
+}
+
+// declaration describes how a location was declared, for lint messages
+func declaration(location *Location) string {
+	if location.Pattern != "" {
+		return fmt.Sprintf("glob %q", location.Pattern)
+	}
+	return fmt.Sprintf("location %q", location.Path)
+}
+
+// yamlKeys returns the keys of the yaml tags of a struct, plus extra keys
+func yamlKeys(value any, extra ...string) map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(value)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if name != "-" {
+			keys[name] = true
+		}
+	}
+	for _, key := range extra {
+		keys[key] = true
+	}
+	return keys
+}
+
+// closestKey returns the key closest to a misspelled one, or "" when none is close
+func closestKey(key string, keys map[string]bool) string {
+	best, bestDistance := "", 3
+	for candidate := range keys {
+		distance := editDistance(key, candidate)
+		if distance < bestDistance || (distance == bestDistance && best != "" && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalars returns a scalar node, or the scalar items of a sequence node
+func scalars(node *yaml.Node) []*yaml.Node {
+	if node == nil {
+		return nil
+	}
+	if node.Kind == yaml.ScalarNode {
+		return []*yaml.Node{node}
+	}
+
+	var items []*yaml.Node
+	for _, item := range node.Content {
+		if item.Kind == yaml.ScalarNode {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// firstColumn returns the column of the leftmost node on a line, or 0
+func firstColumn(node *yaml.Node, line int) int {
+	if node == nil {
+		return 0
+	}
+	column := 0
+	if node.Line == line {
+		column = node.Column
+	}
+	for _, child := range node.Content {
+		if c := firstColumn(child, line); c > 0 && (column == 0 || c < column) {
+			column = c
+		}
+	}
+	return column
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string // "line:column: severity: message" prefixes, in order
+	}{
+		{
+			name: "valid config",
+			files: map[string]string{
+				".gopmrc": `locations:
+  - name: api
+    location: api
+    type: none
+    commands: [make]
+`,
+				"api/Makefile": "",
+			},
+		},
+		{
+			name: "unknown keys",
+			files: map[string]string{
+				".gopmrc": `locations:
+  - name: api
+    location: api
+    type: none
+    comands: [make]
+    commands:
+      - name: serve
+        rn: go run .
+        run: go run .
+shel: bash
+`,
+				"api/Makefile": "",
+			},
+			expected: []string{
+				`5:5: error: unknown key "comands" in location, did you mean "commands"?`,
+				`8:9: error: unknown key "rn" in command, did you mean "run"?`,
+				`10:1: error: unknown key "shel" in config, did you mean "shell"?`,
+			},
+		},
+		{
+			name: "invalid values",
+			files: map[string]string{
+				".gopmrc": `locations:
+  - name: api
+    location: missing
+    type: [none, npmm]
+    commands:
+      - name: serve
+        run: [go, run]
+`,
+			},
+			expected: []string{
+				`3:15: error: directory missing does not exist`,
+				`4:18: error: unknown project type "npmm"`,
+				`7:9: error: cannot unmarshal !!seq into string`,
+			},
+		},
+		{
+			name: "missing include",
+			files: map[string]string{
+				".gopmrc":   "include: [team.yaml, other.yaml]\n",
+				"team.yaml": "locations:\n  - location: .\n    type: none\n    commands: [ls]\n",
+			},
+			expected: []string{
+				`1:22: error: include other.yaml`,
+			},
+		},
+		{
+			name: "lint warnings",
+			files: map[string]string{
+				".gopmrc": `locations:
+  - name: empty
+    location: empty
+    type: none
+  - name: jobs
+    location: "jobs/*"
+    type: none
+    commands: [./run.sh]
+  - name: daily
+    location: jobs/daily
+    type: none
+    commands: [./run.sh]
+`,
+				"empty/README.md":   "",
+				"jobs/daily/run.sh": "",
+				"jobs/x/run.sh":     "",
+			},
+			expected: []string{
+				`2:5: warning: location empty has no commands`,
+				`5:5: warning: location name "daily" is shared by the locations jobs/daily, jobs/daily#2`,
+				`9:5: warning: location "jobs/daily" overlaps glob "jobs/*" (.gopmrc:5): both match jobs/daily`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeFiles(t, tmpDir, tt.files)
+
+			problems, err := Validate(filepath.Join(tmpDir, ".gopmrc"))
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			var got []string
+			for _, problem := range problems {
+				got = append(got, strings.TrimPrefix(problem.String(), filepath.Join(tmpDir, ".gopmrc")+":"))
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Validate() = %q, expected %q", got, tt.expected)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.expected[i]) {
+					t.Errorf("problem %d = %q, expected %q", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateIncludedFilePositions(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".gopmrc":   "include: [team.yaml]\n",
+		"team.yaml": "locations:\n  - location: .\n    typ: none\n    commands: [ls]\n",
+	})
+
+	problems, err := Validate(filepath.Join(tmpDir, ".gopmrc"))
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(problems) != 1 {
+		t.Fatalf("Expected one problem, got %v", problems)
+	}
+
+	expected := Problem{File: filepath.Join(tmpDir, "team.yaml"), Line: 3, Column: 5, Severity: SeverityError, Message: `unknown key "typ" in location, did you mean "type"?`}
+	if problems[0] != expected {
+		t.Errorf("Validate() = %+v, expected %+v", problems[0], expected)
+	}
+	if !HasErrors(problems) {
+		t.Error("HasErrors() = false, expected true")
+	}
+}
+
+func TestValidateMissingFile(t *testing.T) {
+	if _, err := Validate(filepath.Join(t.TempDir(), ".gopmrc")); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+}
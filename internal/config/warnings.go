@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Warning is a problem that did not stop the config from loading, e.g. the parser
+// of one location failing. Source, Line and Column locate the declaration of the
+// location the warning is about, when known.
+type Warning struct {
+	Source  string
+	Line    int
+	Column  int
+	Message string
+}
+
+// String returns the message of the warning
+func (w Warning) String() string {
+	return w.Message
+}
+
+// warn records a warning about a location
+func (c *Config) warn(location *Location, message string) {
+	c.Warnings = append(c.Warnings, Warning{
+		Source:  location.Source,
+		Line:    location.Line,
+		Column:  location.Column,
+		Message: message,
+	})
+}
+
+// warnDuplicateNames warns about location names shared by several locations,
+// once per name at the first of them
+func warnDuplicateNames(config *Config) {
+	duplicates := config.DuplicateNames()
+	names := make([]string, 0, len(duplicates))
+	for name := range duplicates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for i := range config.Locations {
+			if config.Locations[i].DisplayName() == name {
+				config.warn(&config.Locations[i], fmt.Sprintf("location name %q is shared by the locations %s, address them by id",
+					name, strings.Join(duplicates[name], ", ")))
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigParserFailureIsWarning(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".gopmrc": `locations:
+  - location: broken
+    type: npm
+    commands: [ls]
+  - location: web
+    type: npm
+`,
+		"broken/package.json": "{not json",
+		"web/package.json":    `{"scripts": {"dev": "vite"}}`,
+	})
+
+	configPath := filepath.Join(tmpDir, ".gopmrc")
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if len(config.Warnings) != 1 {
+		t.Fatalf("Expected one warning, got %v", config.Warnings)
+	}
+	warning := config.Warnings[0]
+	if warning.Source != configPath || warning.Line != 2 || warning.Column != 5 {
+		t.Errorf("Warning position = %s:%d:%d, expected %s:2:5", warning.Source, warning.Line, warning.Column, configPath)
+	}
+	if !strings.Contains(warning.Message, "failed to parse npm commands for location broken") {
+		t.Errorf("Warning = %q", warning.Message)
+	}
+
+	// The failing location keeps its declared commands, the others load fully
+	if commands := config.Locations[0].Commands; len(commands) != 1 || commands[0].Run != "ls" {
+		t.Errorf("broken commands = %+v, expected only ls", commands)
+	}
+	found := false
+	for _, command := range config.Locations[1].Commands {
+		found = found || command.Run == "npm run dev"
+	}
+	if !found {
+		t.Errorf("Expected npm run dev in web, got %+v", config.Locations[1].Commands)
+	}
+}
+
+func TestLoadConfigDuplicateNameWarning(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".gopmrc": `locations:
+  - name: app
+    location: a
+    type: none
+    commands: [ls]
+  - name: app
+    location: b
+    type: none
+    commands: [ls]
+`,
+	})
+
+	config, err := LoadConfig(filepath.Join(tmpDir, ".gopmrc"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	expected := `location name "app" is shared by the locations a, b, address them by id`
+	if len(config.Warnings) != 1 || config.Warnings[0].String() != expected {
+		t.Errorf("Warnings = %v, expected [%s]", config.Warnings, expected)
+	}
+}
+
+func TestLoadConfigContextCancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		".gopmrc": `locations:
+  - location: web
+    type: npm
+`,
+		"web/package.json": `{"scripts": {"dev": "vite"}}`,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := LoadConfigContext(ctx, filepath.Join(tmpDir, ".gopmrc"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("LoadConfigContext() error = %v, expected %v", err, context.Canceled)
+	}
+}
+
+func TestLoadConfigParsesLocationsInOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{}
+	var gopmrc strings.Builder
+	gopmrc.WriteString("locations:\n")
+	count := 2 * parseWorkers
+	for i := 0; i < count; i++ {
+		dir := fmt.Sprintf("app%02d", i)
+		fmt.Fprintf(&gopmrc, "  - location: %s\n    type: npm\n", dir)
+		if i%2 == 0 {
+			files[dir+"/package.json"] = "{not json"
+		} else {
+			files[dir+"/package.json"] = fmt.Sprintf(`{"scripts": {"%s": "vite"}}`, dir)
+		}
+	}
+	files[".gopmrc"] = gopmrc.String()
+	writeFiles(t, tmpDir, files)
+
+	config, err := LoadConfig(filepath.Join(tmpDir, ".gopmrc"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	// Results and warnings line up with the locations, whichever worker parsed them
+	for i, location := range config.Locations {
+		dir := fmt.Sprintf("app%02d", i)
+		if i%2 == 0 {
+			continue
+		}
+		if last := location.Commands[len(location.Commands)-1]; last.Run != "npm run "+dir {
+			t.Errorf("%s last command = %q, expected npm run %s", dir, last.Run, dir)
+		}
+	}
+	if len(config.Warnings) != count/2 {
+		t.Fatalf("Expected a warning per broken location, got %v", config.Warnings)
+	}
+	for i, warning := range config.Warnings {
+		if expected := fmt.Sprintf("location app%02d ", 2*i); !strings.Contains(warning.Message, expected) {
+			t.Errorf("Warning %d = %q, expected it to be about %s", i, warning.Message, expected)
+		}
+	}
+}
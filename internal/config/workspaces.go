@@ -0,0 +1,361 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// WorkspacesAuto imports every workspace manifest found next to the config file
+const WorkspacesAuto = "auto"
+
+// workspaceManifest describes a native workspace definition, e.g. go.work
+type workspaceManifest struct {
+	// file is the manifest, looked up in the directory of the config file
+	file string
+
+	// marker is a file every member directory contains
+	marker string
+
+	// patterns reads the member patterns from the manifest. Patterns starting
+	// with ! exclude directories. found is false when the manifest is missing or
+	// does not define a workspace.
+	patterns func(path string) (patterns []string, found bool, err error)
+
+	// name returns the name of a member from its own manifest, or ""
+	name func(dir string) string
+}
+
+var workspaceManifests = map[string]workspaceManifest{
+	"pnpm":  {file: "pnpm-workspace.yaml", marker: "package.json", patterns: pnpmWorkspacePatterns, name: packageJSONName},
+	"npm":   {file: "package.json", marker: "package.json", patterns: npmWorkspacePatterns, name: packageJSONName},
+	"lerna": {file: "lerna.json", marker: "package.json", patterns: lernaWorkspacePatterns, name: packageJSONName},
+	"nx":    {file: "nx.json", marker: "project.json", patterns: nxWorkspacePatterns, name: nxProjectName},
+	"go":    {file: "go.work", marker: "go.mod", patterns: goWorkspacePatterns, name: goModulePath},
+	"cargo": {file: "Cargo.toml", marker: "Cargo.toml", patterns: cargoWorkspacePatterns, name: cargoPackageName},
+}
+
+// workspaceOrder is the order workspaces: auto reads the manifests in
+var workspaceOrder = []string{"pnpm", "npm", "lerna", "nx", "go", "cargo"}
+
+// workspaceLocations returns a location for every member of the workspaces named
+// in the config. Members are named after their manifest, e.g. the package.json
+// name or the go.mod module path. Directories that are already a location are left out.
+func workspaceLocations(config *Config) ([]Location, error) {
+	kinds := config.Workspaces
+	auto := len(kinds) == 1 && kinds[0] == WorkspacesAuto
+	if auto {
+		kinds = workspaceOrder
+	}
+
+	known := make(map[string]bool)
+	for _, location := range config.Locations {
+		known[location.Location] = true
+	}
+
+	var locations []Location
+	for _, kind := range kinds {
+		manifest, ok := workspaceManifests[kind]
+		if !ok {
+			return nil, fmt.Errorf("unknown workspace %q (expected %s or one of %s)", kind, WorkspacesAuto, strings.Join(workspaceOrder, ", "))
+		}
+
+		path := filepath.Join(config.Dir, manifest.file)
+		patterns, found, err := manifest.patterns(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read workspace %s: %w", path, err)
+		}
+		if !found {
+			if auto {
+				continue
+			}
+			return nil, fmt.Errorf("workspace %s: %s not found or defines no workspace", kind, path)
+		}
+
+		dirs, err := expandWorkspacePatterns(config.Dir, patterns, manifest.marker)
+		if err != nil {
+			return nil, fmt.Errorf("workspace %s: %w", kind, err)
+		}
+		for _, dir := range dirs {
+			if known[dir] {
+				continue
+			}
+			known[dir] = true
+			locations = append(locations, Location{
+				Name:     manifest.name(dir),
+				Location: dir,
+				Path:     relativePath(dir, config.Dir),
+			})
+		}
+	}
+	return locations, nil
+}
+
+// expandWorkspacePatterns expands member patterns relative to dir into the member
+// directories containing marker, in pattern order with the matches of a glob sorted
+func expandWorkspacePatterns(dir string, patterns []string, marker string) ([]string, error) {
+	var includes, excludes []string
+	for _, pattern := range patterns {
+		if exclude, ok := strings.CutPrefix(pattern, "!"); ok {
+			excludes = append(excludes, exclude)
+			continue
+		}
+		includes = append(includes, pattern)
+	}
+
+	var members []Location
+	for _, pattern := range includes {
+		members = append(members, Location{Location: filepath.Join(dir, pattern), Exclude: excludes})
+	}
+
+	expanded, err := expandGlobPatterns(members, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, member := range expanded {
+		if seen[member.Location] || !hasMarker(member.Location, []string{marker}) || isExcluded(member.Location, excludes, dir) {
+			continue
+		}
+		seen[member.Location] = true
+		dirs = append(dirs, member.Location)
+	}
+	return dirs, nil
+}
+
+// readManifest reads a manifest, reporting a missing file as not found
+func readManifest(path string) ([]byte, bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// pnpmWorkspacePatterns reads the packages of pnpm-workspace.yaml
+func pnpmWorkspacePatterns(path string) ([]string, bool, error) {
+	data, found, err := readManifest(path)
+	if !found || err != nil {
+		return nil, found, err
+	}
+
+	var manifest struct {
+		Packages []string `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, false, err
+	}
+	return manifest.Packages, len(manifest.Packages) > 0, nil
+}
+
+// npmWorkspacePatterns reads the workspaces field of package.json, used by npm,
+// yarn and bun. It is either a list or an object with a packages list.
+func npmWorkspacePatterns(path string) ([]string, bool, error) {
+	data, found, err := readManifest(path)
+	if !found || err != nil {
+		return nil, found, err
+	}
+
+	var manifest struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, false, err
+	}
+	if len(manifest.Workspaces) == 0 {
+		return nil, false, nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(manifest.Workspaces, &patterns); err != nil {
+		var object struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(manifest.Workspaces, &object); err != nil {
+			return nil, false, fmt.Errorf("invalid workspaces field: %w", err)
+		}
+		patterns = object.Packages
+	}
+	return patterns, len(patterns) > 0, nil
+}
+
+// lernaWorkspacePatterns reads the packages of lerna.json, which default to packages/*
+func lernaWorkspacePatterns(path string) ([]string, bool, error) {
+	data, found, err := readManifest(path)
+	if !found || err != nil {
+		return nil, found, err
+	}
+
+	var manifest struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, false, err
+	}
+	if len(manifest.Packages) == 0 {
+		return []string{"packages/*"}, true, nil
+	}
+	return manifest.Packages, true, nil
+}
+
+// nxWorkspacePatterns finds nx projects below the apps and libs directories of nx.json
+func nxWorkspacePatterns(path string) ([]string, bool, error) {
+	data, found, err := readManifest(path)
+	if !found || err != nil {
+		return nil, found, err
+	}
+
+	var manifest struct {
+		WorkspaceLayout struct {
+			AppsDir string `json:"appsDir"`
+			LibsDir string `json:"libsDir"`
+		} `json:"workspaceLayout"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, false, err
+	}
+
+	appsDir, libsDir := manifest.WorkspaceLayout.AppsDir, manifest.WorkspaceLayout.LibsDir
+	if appsDir == "" {
+		appsDir = "apps"
+	}
+	if libsDir == "" {
+		libsDir = "libs"
+	}
+	return []string{appsDir + "/**", libsDir + "/**"}, true, nil
+}
+
+// goWorkspacePatterns reads the use directives of go.work
+func goWorkspacePatterns(path string) ([]string, bool, error) {
+	data, found, err := readManifest(path)
+	if !found || err != nil {
+		return nil, found, err
+	}
+
+	var patterns []string
+	inBlock := false
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		line = strings.TrimSpace(line)
+
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			patterns = append(patterns, unquoteGoPath(line))
+		case line == "use (":
+			inBlock = true
+		case strings.HasPrefix(line, "use "):
+			patterns = append(patterns, unquoteGoPath(strings.TrimSpace(strings.TrimPrefix(line, "use "))))
+		}
+	}
+	return patterns, len(patterns) > 0, nil
+}
+
+func unquoteGoPath(path string) string {
+	return strings.Trim(path, "\"`")
+}
+
+// cargoWorkspacePatterns reads [workspace] members and exclude of Cargo.toml
+func cargoWorkspacePatterns(path string) ([]string, bool, error) {
+	data, found, err := readManifest(path)
+	if !found || err != nil {
+		return nil, found, err
+	}
+
+	var manifest map[string]any
+	if err := toml.Unmarshal(data, &manifest); err != nil {
+		return nil, false, err
+	}
+	workspace, ok := manifest["workspace"].(map[string]any)
+	if !ok {
+		return nil, false, nil
+	}
+
+	patterns := tomlStrings(workspace["members"])
+	for _, exclude := range tomlStrings(workspace["exclude"]) {
+		patterns = append(patterns, "!"+exclude)
+	}
+	return patterns, len(patterns) > 0, nil
+}
+
+// tomlStrings returns the strings of a decoded TOML array
+func tomlStrings(value any) []string {
+	list, _ := value.([]any)
+	var result []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// packageJSONName returns the name field of dir/package.json
+func packageJSONName(dir string) string {
+	return jsonName(filepath.Join(dir, "package.json"))
+}
+
+// nxProjectName returns the name field of dir/project.json, or of package.json
+func nxProjectName(dir string) string {
+	if name := jsonName(filepath.Join(dir, "project.json")); name != "" {
+		return name
+	}
+	return packageJSONName(dir)
+}
+
+func jsonName(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var manifest struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return ""
+	}
+	return manifest.Name
+}
+
+// goModulePath returns the module path of dir/go.mod
+func goModulePath(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return unquoteGoPath(strings.TrimSpace(module))
+		}
+	}
+	return ""
+}
+
+// cargoPackageName returns [package] name of dir/Cargo.toml
+func cargoPackageName(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		return ""
+	}
+	var manifest map[string]any
+	if err := toml.Unmarshal(data, &manifest); err != nil {
+		return ""
+	}
+	pkg, _ := manifest["package"].(map[string]any)
+	name, _ := pkg["name"].(string)
+	return name
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for file, content := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWorkspaceLocations(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		workspaces []string
+		expected   []Location
+		wantErr    string
+	}{
+		{
+			name: "pnpm with negated pattern",
+			files: map[string]string{
+				"pnpm-workspace.yaml":            "packages:\n  - \"packages/*\"\n  - \"!packages/internal\"\n",
+				"packages/ui/package.json":       `{"name": "@acme/ui"}`,
+				"packages/internal/package.json": `{"name": "@acme/internal"}`,
+				"packages/notes/README.md":       "no package.json\n",
+			},
+			workspaces: []string{"pnpm"},
+			expected: []Location{
+				{Name: "@acme/ui", Path: "packages/ui"},
+			},
+		},
+		{
+			name: "package.json workspaces object",
+			files: map[string]string{
+				"package.json":          `{"name": "root", "workspaces": {"packages": ["apps/*"]}}`,
+				"apps/web/package.json": `{"name": "web-app"}`,
+				"apps/api/package.json": `{}`,
+			},
+			workspaces: []string{"npm"},
+			expected: []Location{
+				{Name: "", Path: "apps/api"},
+				{Name: "web-app", Path: "apps/web"},
+			},
+		},
+		{
+			name: "lerna defaults to packages/*",
+			files: map[string]string{
+				"lerna.json":              `{"version": "1.0.0"}`,
+				"packages/a/package.json": `{"name": "a"}`,
+				"modules/b/package.json":  `{"name": "b"}`,
+			},
+			workspaces: []string{"lerna"},
+			expected: []Location{
+				{Name: "a", Path: "packages/a"},
+			},
+		},
+		{
+			name: "nx projects",
+			files: map[string]string{
+				"nx.json":                     `{"workspaceLayout": {"libsDir": "packages"}}`,
+				"apps/shop/project.json":      `{"name": "shop"}`,
+				"packages/ui/project.json":    `{"name": "ui"}`,
+				"packages/ui/src/project.txt": "",
+			},
+			workspaces: []string{"nx"},
+			expected: []Location{
+				{Name: "shop", Path: "apps/shop"},
+				{Name: "ui", Path: "packages/ui"},
+			},
+		},
+		{
+			name: "go.work",
+			files: map[string]string{
+				"go.work":       "go 1.22\n\nuse ./tools // linters\n\nuse (\n\t./api\n\t\"./cli\"\n)\n",
+				"api/go.mod":    "module example.com/api\n\ngo 1.22\n",
+				"cli/go.mod":    "module example.com/cli\n",
+				"tools/go.mod":  "module example.com/tools\n",
+				"unused/go.mod": "module example.com/unused\n",
+			},
+			workspaces: []string{"go"},
+			expected: []Location{
+				{Name: "example.com/tools", Path: "tools"},
+				{Name: "example.com/api", Path: "api"},
+				{Name: "example.com/cli", Path: "cli"},
+			},
+		},
+		{
+			name: "cargo workspace",
+			files: map[string]string{
+				"Cargo.toml":             "[workspace]\nmembers = [\"crates/*\"]\nexclude = [\"crates/old\"]\n",
+				"crates/core/Cargo.toml": "[package]\nname = \"acme-core\"\n",
+				"crates/old/Cargo.toml":  "[package]\nname = \"acme-old\"\n",
+			},
+			workspaces: []string{"cargo"},
+			expected: []Location{
+				{Name: "acme-core", Path: "crates/core"},
+			},
+		},
+		{
+			name: "auto reads every manifest once",
+			files: map[string]string{
+				"pnpm-workspace.yaml":     "packages: [\"packages/*\"]\n",
+				"package.json":            `{"workspaces": ["packages/*"]}`,
+				"packages/a/package.json": `{"name": "a"}`,
+				"go.work":                 "use ./svc\n",
+				"svc/go.mod":              "module svc\n",
+				"Cargo.toml":              "[package]\nname = \"not-a-workspace\"\n",
+			},
+			workspaces: []string{WorkspacesAuto},
+			expected: []Location{
+				{Name: "a", Path: "packages/a"},
+				{Name: "svc", Path: "svc"},
+			},
+		},
+		{
+			name:       "missing manifest",
+			files:      map[string]string{"package.json": `{"name": "root"}`},
+			workspaces: []string{"npm"},
+			wantErr:    "defines no workspace",
+		},
+		{
+			name:       "unknown workspace",
+			workspaces: []string{"maven"},
+			wantErr:    `unknown workspace "maven"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeFiles(t, tmpDir, tt.files)
+
+			config := &Config{Dir: tmpDir, Workspaces: tt.workspaces}
+			locations, err := workspaceLocations(config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("workspaceLocations() error = %v, expected it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("workspaceLocations() error = %v", err)
+			}
+
+			if len(locations) != len(tt.expected) {
+				t.Fatalf("workspaceLocations() returned %d locations, expected %d: %+v", len(locations), len(tt.expected), locations)
+			}
+			for i, location := range locations {
+				expected := tt.expected[i]
+				if location.Name != expected.Name {
+					t.Errorf("Location[%d].Name = %q, expected %q", i, location.Name, expected.Name)
+				}
+				if location.Path != expected.Path {
+					t.Errorf("Location[%d].Path = %q, expected %q", i, location.Path, expected.Path)
+				}
+				if location.Location != filepath.Join(tmpDir, expected.Path) {
+					t.Errorf("Location[%d].Location = %q, expected it under %s", i, location.Location, tmpDir)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigWithWorkspaces(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"go.work":       "use (\n\t./api\n\t./worker\n)\n",
+		"api/go.mod":    "module example.com/api\n",
+		"worker/go.mod": "module example.com/worker\n",
+		".gopmrc":       "workspaces: go\nlocations:\n  - name: api\n    location: api\n    type: none\n",
+	})
+
+	config, err := LoadConfig(filepath.Join(tmpDir, ".gopmrc"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	// The declared api location wins over the workspace member
+	if len(config.Locations) != 2 {
+		t.Fatalf("Expected 2 locations, got %d", len(config.Locations))
+	}
+	if config.Locations[0].Name != "api" || config.Locations[1].Name != "example.com/worker" {
+		t.Errorf("Unexpected locations %q and %q", config.Locations[0].Name, config.Locations[1].Name)
+	}
+	if config.Locations[1].Type != "go" {
+		t.Errorf("Expected the worker to be detected as go, got %q", config.Locations[1].Type)
+	}
+}
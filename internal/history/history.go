@@ -0,0 +1,222 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Limits of the history file: entries last used maxAge before the latest run are
+// dropped, and only the maxEntries most recently used are kept
+const (
+	maxAge     = 180 * 24 * time.Hour
+	maxEntries = 1000
+)
+
+// Entry records how often and how recently a command was run in a directory
+type Entry struct {
+	Directory string    `json:"directory"`
+	Command   string    `json:"command"`
+	Count     int       `json:"count"`
+	LastUsed  time.Time `json:"last_used"`
+}
+
+// History is the list of commands run through gopm
+type History struct {
+	Entries []Entry `json:"entries"`
+
+	path string
+}
+
+// DefaultPath returns the location of the history file, ~/.gopm/history.json
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".gopm", "history.json"), nil
+}
+
+// Load reads the history file at path. A missing file is an empty history.
+func Load(path string) (*History, error) {
+	history := &History{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("failed to parse history %s: %w", path, err)
+	}
+	return history, nil
+}
+
+// LoadDefault reads the history file at DefaultPath
+func LoadDefault() (*History, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// Update loads the history at path, applies update to it and saves it. The
+// history is locked meanwhile, through a path.lock file next to it, so that
+// concurrent runs of gopm do not lose each other's records.
+func Update(path string, update func(*History)) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	history, err := Load(path)
+	if err != nil {
+		return err
+	}
+	update(history)
+	return history.Save()
+}
+
+// UpdateDefault is Update for the history file at DefaultPath
+func UpdateDefault(update func(*History)) error {
+	path, err := DefaultPath()
+	if err != nil {
+		return err
+	}
+	return Update(path, update)
+}
+
+// Record counts a run of command in directory at the given time
+func (h *History) Record(directory, command string, now time.Time) {
+	for i := range h.Entries {
+		entry := &h.Entries[i]
+		if entry.Directory == directory && entry.Command == command {
+			entry.Count++
+			entry.LastUsed = now
+			return
+		}
+	}
+
+	h.Entries = append(h.Entries, Entry{Directory: directory, Command: command, Count: 1, LastUsed: now})
+}
+
+// Save writes the history back to the file it was loaded from, without the
+// entries that are too old or too many, see prune. Use Update to read and save
+// the history without losing the records of concurrent runs.
+func (h *History) Save() error {
+	h.prune()
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+
+	// Write to a temporary file first so concurrent runs never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), ".history-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), h.path); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// prune drops the entries last used more than maxAge before the latest entry,
+// then the least recently used entries beyond maxEntries. The others keep their order.
+func (h *History) prune() {
+	var latest time.Time
+	for _, entry := range h.Entries {
+		if entry.LastUsed.After(latest) {
+			latest = entry.LastUsed
+		}
+	}
+
+	kept := h.Entries[:0]
+	for _, entry := range h.Entries {
+		if latest.Sub(entry.LastUsed) <= maxAge {
+			kept = append(kept, entry)
+		}
+	}
+	h.Entries = kept
+	if len(h.Entries) <= maxEntries {
+		return
+	}
+
+	byRecency := make([]Entry, len(h.Entries))
+	copy(byRecency, h.Entries)
+	sort.SliceStable(byRecency, func(i, j int) bool {
+		return byRecency[i].LastUsed.After(byRecency[j].LastUsed)
+	})
+	cutoff := byRecency[maxEntries-1].LastUsed
+	// Entries used at the cutoff time fill the places left by the newer ones
+	ties := 0
+	for _, entry := range byRecency[:maxEntries] {
+		if entry.LastUsed.Equal(cutoff) {
+			ties++
+		}
+	}
+
+	kept = h.Entries[:0]
+	for _, entry := range h.Entries {
+		switch {
+		case entry.LastUsed.After(cutoff):
+			kept = append(kept, entry)
+		case entry.LastUsed.Equal(cutoff) && ties > 0:
+			kept = append(kept, entry)
+			ties--
+		}
+	}
+	h.Entries = kept
+}
+
+// Frecency scores a command by how often and how recently it was run in directory.
+// Commands that were never run score 0.
+func (h *History) Frecency(directory, command string, now time.Time) float64 {
+	for _, entry := range h.Entries {
+		if entry.Directory == directory && entry.Command == command {
+			return float64(entry.Count) * recencyWeight(now.Sub(entry.LastUsed))
+		}
+	}
+	return 0
+}
+
+// recencyWeight favors commands run recently
+func recencyWeight(age time.Duration) float64 {
+	switch {
+	case age < time.Hour:
+		return 4
+	case age < 24*time.Hour:
+		return 2
+	case age < 7*24*time.Hour:
+		return 1
+	case age < 30*24*time.Hour:
+		return 0.5
+	default:
+		return 0.25
+	}
+}
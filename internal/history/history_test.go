@@ -0,0 +1,150 @@
+package history
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	history, err := Load(filepath.Join(t.TempDir(), "history.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(history.Entries) != 0 {
+		t.Errorf("Expected empty history, got %v", history.Entries)
+	}
+}
+
+func TestRecordAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.json")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	history, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	history.Record("/repo/web", "npm test", now.Add(-time.Hour))
+	history.Record("/repo/web", "npm test", now)
+	history.Record("/repo/api", "npm test", now)
+	if err := history.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %v", loaded.Entries)
+	}
+	entry := loaded.Entries[0]
+	if entry.Directory != "/repo/web" || entry.Count != 2 || !entry.LastUsed.Equal(now) {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+}
+
+func TestUpdateKeepsConcurrentRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	const runs = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, runs)
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- Update(path, func(history *History) {
+				history.Record("/repo/web", "npm test", now)
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Entries) != 1 || loaded.Entries[0].Count != runs {
+		t.Errorf("Expected one entry run %d times, got %+v", runs, loaded.Entries)
+	}
+}
+
+func TestSavePrunesOldEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	history, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	history.Record("/repo", "stale", now.Add(-maxAge-time.Hour))
+	history.Record("/repo", "recent", now)
+	if err := history.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Entries) != 1 || loaded.Entries[0].Command != "recent" {
+		t.Errorf("Expected only the recent entry, got %v", loaded.Entries)
+	}
+}
+
+func TestPruneKeepsMostRecentEntries(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	history := &History{}
+	// Two entries share the oldest time, only one of them fits
+	history.Record("/repo", "oldest", now.Add(-2*time.Hour))
+	history.Record("/repo", "tied", now.Add(-time.Hour))
+	history.Record("/repo", "also tied", now.Add(-time.Hour))
+	for i := 0; i < maxEntries-2; i++ {
+		history.Record("/repo", fmt.Sprintf("command %d", i), now)
+	}
+	history.Record("/repo", "newest", now.Add(time.Minute))
+
+	history.prune()
+
+	if len(history.Entries) != maxEntries {
+		t.Fatalf("Expected %d entries, got %d", maxEntries, len(history.Entries))
+	}
+	if history.Entries[0].Command != "tied" {
+		t.Errorf("Expected the first tied entry to be kept first, got %+v", history.Entries[0])
+	}
+	if last := history.Entries[len(history.Entries)-1]; last.Command != "newest" {
+		t.Errorf("Expected the newest entry to be kept, got %+v", last)
+	}
+}
+
+func TestFrecency(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	history := &History{Entries: []Entry{
+		{Directory: "/repo", Command: "recent", Count: 1, LastUsed: now.Add(-time.Minute)},
+		{Directory: "/repo", Command: "frequent", Count: 10, LastUsed: now.Add(-48 * time.Hour)},
+		{Directory: "/repo", Command: "old", Count: 2, LastUsed: now.Add(-90 * 24 * time.Hour)},
+	}}
+
+	recent := history.Frecency("/repo", "recent", now)
+	frequent := history.Frecency("/repo", "frequent", now)
+	old := history.Frecency("/repo", "old", now)
+	never := history.Frecency("/repo", "never", now)
+	other := history.Frecency("/other", "recent", now)
+
+	if !(frequent > recent && recent > old && old > never) {
+		t.Errorf("Unexpected scores: frequent=%v recent=%v old=%v never=%v", frequent, recent, old, never)
+	}
+	if never != 0 || other != 0 {
+		t.Errorf("Expected unknown commands to score 0, got %v and %v", never, other)
+	}
+}
@@ -1,28 +1,36 @@
 package parsers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 )
 
 // PackageJsonParser parses package.json scripts
 type PackageJsonParser struct{}
 
-func (p *PackageJsonParser) ParseCommands(directory string, config ParserConfig) ([]string, error) {
+func (p *PackageJsonParser) ParseCommands(ctx context.Context, directory string, config ParserConfig) ([]ParsedCommand, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	packageJsonPath := filepath.Join(directory, "package.json")
-	return parsePackageJsonScripts(packageJsonPath)
+	scripts, err := parsePackageJsonScripts(packageJsonPath)
+	if err != nil {
+		return nil, err
+	}
+	return keyCommands(scripts), nil
 }
 
 // GoStandardParser provides standard Go commands
 type GoStandardParser struct{}
 
-func (g *GoStandardParser) ParseCommands(directory string, config ParserConfig) ([]string, error) {
+func (g *GoStandardParser) ParseCommands(ctx context.Context, directory string, config ParserConfig) ([]ParsedCommand, error) {
 	// For Go projects, we don't parse the go.mod file for commands
 	// Instead, we return standard Go commands that are commonly used
-	return []string{
+	return keyCommands([]string{
 		"run",
 		"build",
 		"test",
@@ -35,7 +43,7 @@ func (g *GoStandardParser) ParseCommands(directory string, config ParserConfig)
 		"generate",
 		"doc",
 		"version",
-	}, nil
+	}), nil
 }
 
 // PackageJson represents the structure of a package.json file
@@ -45,6 +53,7 @@ type PackageJson struct {
 }
 
 // parsePackageJsonScripts parses a package.json file and extracts script names
+// in the order they appear in the file
 func parsePackageJsonScripts(configPath string) ([]string, error) {
 	// Read the package.json file
 	data, err := os.ReadFile(configPath)
@@ -52,23 +61,54 @@ func parsePackageJsonScripts(configPath string) ([]string, error) {
 		return nil, fmt.Errorf("failed to read package.json: %w", err)
 	}
 
-	// Parse JSON
-	var packageJson PackageJson
+	// Parse JSON, keeping the scripts raw so their order survives
+	var packageJson struct {
+		Scripts json.RawMessage `json:"scripts"`
+	}
 	if err := json.Unmarshal(data, &packageJson); err != nil {
 		return nil, fmt.Errorf("failed to parse package.json: %w", err)
 	}
+	if len(packageJson.Scripts) == 0 || string(packageJson.Scripts) == "null" {
+		return nil, nil
+	}
 
 	// Extract script names (only string values)
 	var commands []string
-	for scriptName, scriptValue := range packageJson.Scripts {
-		// Only include scripts that have string values
-		if _, ok := scriptValue.(string); ok {
-			commands = append(commands, scriptName)
+	err = walkJSONObject(packageJson.Scripts, func(key string, value json.RawMessage) {
+		if len(value) > 0 && value[0] == '"' {
+			commands = append(commands, key)
 		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse package.json scripts: %w", err)
 	}
 
-	// Sort commands for consistent output
-	sort.Strings(commands)
-
 	return commands, nil
-}
\ No newline at end of file
+}
+
+// walkJSONObject calls visit for each member of a JSON object in document order
+func walkJSONObject(data json.RawMessage, visit func(key string, value json.RawMessage)) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected an object")
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		visit(key, value)
+	}
+	return nil
+}
@@ -1,23 +1,32 @@
 package parsers
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 )
 
+// waitDelay is how long a cancelled parser command may take to exit and close
+// its output before gopm stops waiting for it
+const waitDelay = 2 * time.Second
+
 // CommandParser executes a shell command to parse project commands
 type CommandParser struct{}
 
-func (c *CommandParser) ParseCommands(directory string, config ParserConfig) ([]string, error) {
+func (c *CommandParser) ParseCommands(ctx context.Context, directory string, config ParserConfig) ([]ParsedCommand, error) {
 	if config.ParserCommand == "" {
-		return []string{}, nil
+		return []ParsedCommand{}, nil
 	}
 
-	// Execute the parser command in the project directory
-	cmd := exec.Command("sh", "-c", config.ParserCommand)
+	// Execute the parser command in the project directory, killing it and
+	// whatever it started when ctx is done
+	cmd := exec.CommandContext(ctx, "sh", "-c", config.ParserCommand)
 	cmd.Dir = directory
+	setProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
 
 	// Set up environment
 	cmd.Env = os.Environ()
@@ -25,23 +34,32 @@ func (c *CommandParser) ParseCommands(directory string, config ParserConfig) ([]
 	// Execute the command
 	output, err := cmd.Output()
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return nil, fmt.Errorf("failed to execute parser command '%s': %w", config.ParserCommand, err)
 	}
 
-	// Parse the output - each line is a command
-	outputStr := strings.TrimSpace(string(output))
-	if outputStr == "" {
-		return []string{}, nil
+	switch config.ParserOutput {
+	case OutputJSON, OutputJSONL:
+		commands, err := parseJSONOutput(output)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output of parser command '%s': %w", config.ParserCommand, err)
+		}
+		return commands, nil
+	default:
+		return keyCommands(parseLineOutput(output)), nil
 	}
+}
 
-	lines := strings.Split(outputStr, "\n")
-	var commands []string
-	for _, line := range lines {
+// parseLineOutput returns the non-empty lines of the output, the keys of the commands
+func parseLineOutput(output []byte) []string {
+	var keys []string
+	for _, line := range strings.Split(string(output), "\n") {
 		line = strings.TrimSpace(line)
 		if line != "" {
-			commands = append(commands, line)
+			keys = append(keys, line)
 		}
 	}
-
-	return commands, nil
-}
\ No newline at end of file
+	return keys
+}
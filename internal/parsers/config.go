@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -19,15 +21,112 @@ type ParserConfig struct {
 	
 	// BaseCommands are commands that are always available, regardless of parsing
 	BaseCommands map[string]string `yaml:"base_commands"`
+
+	// BaseCommandOrder lists the keys of BaseCommands in the order they were written
+	BaseCommandOrder []string `yaml:"-"`
 	
 	// BuiltinParser specifies a built-in parser to use (e.g., "package_json_scripts")
 	BuiltinParser string `yaml:"builtin_parser,omitempty"`
 	
+	// File, Format and Path configure the structured builtin parser, see StructuredParser.
+	// File is relative to the project directory.
+	File   string `yaml:"file,omitempty"`
+	Format string `yaml:"format,omitempty"`
+	Path   string `yaml:"path,omitempty"`
+
+	// DescriptionField names the field holding the description of entries that are objects
+	DescriptionField string `yaml:"description_field,omitempty"`
+
+	// Descriptions is the path of a map from key to description, next to the listed map
+	Descriptions string `yaml:"descriptions,omitempty"`
+
+	// Pattern, DescriptionPattern, IncludePattern and NamespacePattern configure
+	// the regex builtin parser, see RegexParser. It reads File as well.
+	Pattern            string `yaml:"pattern,omitempty"`
+	DescriptionPattern string `yaml:"description_pattern,omitempty"`
+	IncludePattern     string `yaml:"include_pattern,omitempty"`
+	NamespacePattern   string `yaml:"namespace_pattern,omitempty"`
+
+	// ExcludeTargets hides targets of the makefile builtin parser, by name or glob pattern
+	ExcludeTargets []string `yaml:"exclude_targets,omitempty"`
+
 	// ParserCommand is a shell command that outputs available commands (one per line)
 	ParserCommand string `yaml:"parser_command,omitempty"`
+
+	// ParserOutput is the format of the parser command output: "lines" (the
+	// default, one key per line) or "json" (command objects, see ParsedCommand)
+	ParserOutput string `yaml:"parser_output,omitempty"`
 	
 	// CommandTemplate is how to construct the final command (e.g., "npm run {key}")
 	CommandTemplate string `yaml:"command_template,omitempty"`
+
+	// Priority breaks ties when several parsers match a directory (higher wins)
+	Priority int `yaml:"priority,omitempty"`
+
+	// LockFiles mark this parser as the one in use when found in the directory
+	// or any parent up to the workspace root (e.g., "pnpm-lock.yaml")
+	LockFiles []string `yaml:"lock_files,omitempty"`
+
+	// PackageManager is matched against the "packageManager" field of package.json
+	PackageManager string `yaml:"package_manager,omitempty"`
+
+	// Group marks parsers that are alternatives to each other (e.g., "node" for npm and yarn).
+	// Only the best matching parser of a group is applied to a directory.
+	Group string `yaml:"group,omitempty"`
+
+	// Timeout bounds how long the parser may run (e.g., "10s"), DefaultTimeout when unset
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// UnmarshalYAML decodes a parser configuration, remembering the order of base commands
+func (c *ParserConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain ParserConfig
+	var raw plain
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	*c = ParserConfig(raw)
+
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if value.Content[i].Value != "base_commands" {
+			continue
+		}
+		commands := value.Content[i+1]
+		for j := 0; j+1 < len(commands.Content); j += 2 {
+			c.BaseCommandOrder = append(c.BaseCommandOrder, commands.Content[j].Value)
+		}
+	}
+	return nil
+}
+
+// ParseTimeout returns how long the parser may run
+func (c ParserConfig) ParseTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+// BaseCommandKeys returns the keys of BaseCommands in the order they were written.
+// Keys missing from BaseCommandOrder (e.g., set from code) follow in alphabetical order.
+func (c ParserConfig) BaseCommandKeys() []string {
+	keys := make([]string, 0, len(c.BaseCommands))
+	seen := make(map[string]bool, len(c.BaseCommands))
+	for _, key := range c.BaseCommandOrder {
+		if _, ok := c.BaseCommands[key]; ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	var rest []string
+	for key := range c.BaseCommands {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
 }
 
 // ParsersFile represents the entire parsers.yaml configuration
@@ -104,15 +203,18 @@ func (p *ParsersFile) GetParser(name string) (ParserConfig, bool) {
 	return parser, exists
 }
 
-// FindParserForDirectory finds a parser that matches files in the given directory
-func (p *ParsersFile) FindParserForDirectory(directory string) (string, ParserConfig, error) {
-	for name, parser := range p.Parsers {
-		for _, detectFile := range parser.DetectFiles {
-			filePath := filepath.Join(directory, detectFile)
-			if _, err := os.Stat(filePath); err == nil {
-				return name, parser, nil
+// DetectFiles returns the detect files of every parser, sorted and without duplicates
+func (p *ParsersFile) DetectFiles() []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, parser := range p.Parsers {
+		for _, file := range parser.DetectFiles {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
 			}
 		}
 	}
-	return "", ParserConfig{}, fmt.Errorf("no parser found for directory: %s", directory)
-}
\ No newline at end of file
+	sort.Strings(files)
+	return files
+}
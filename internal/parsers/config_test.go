@@ -106,4 +106,25 @@ func TestParserConfigGetParser(t *testing.T) {
 	if exists {
 		t.Error("Expected nonexistent parser to not exist")
 	}
-}
\ No newline at end of file
+}
+func TestParsersFileDetectFiles(t *testing.T) {
+	parsersFile := &ParsersFile{
+		Parsers: map[string]ParserConfig{
+			"npm":  {DetectFiles: []string{"package.json"}},
+			"yarn": {DetectFiles: []string{"package.json"}},
+			"make": {DetectFiles: []string{"Makefile", "makefile"}},
+			"none": {},
+		},
+	}
+
+	files := parsersFile.DetectFiles()
+	expected := []string{"Makefile", "makefile", "package.json"}
+	if len(files) != len(expected) {
+		t.Fatalf("DetectFiles() = %v, expected %v", files, expected)
+	}
+	for i := range expected {
+		if files[i] != expected[i] {
+			t.Errorf("DetectFiles()[%d] = %q, expected %q", i, files[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// workspaceRootMarkers stop the upward search for lock files and package.json
+var workspaceRootMarkers = []string{".git", ".hg", ".svn"}
+
+// FindParserForDirectory finds the parser that best matches files in the given directory.
+//
+// A parser matches when one of its detect_files exists in the directory. When several
+// parsers match, the first rule that decides wins:
+//  1. the "packageManager" field of the nearest package.json names a parser's package_manager
+//  2. the nearest lock file (searching up to the workspace root) belongs to a parser
+//  3. the parser with the highest priority, then the alphabetically first name
+func (p *ParsersFile) FindParserForDirectory(directory string) (string, ParserConfig, error) {
+	candidates := p.matchingParsers(directory)
+	if len(candidates) == 0 {
+		return "", ParserConfig{}, fmt.Errorf("no parser found for directory: %s", directory)
+	}
+
+	name := p.bestMatch(directory, candidates)
+	return name, p.Parsers[name], nil
+}
+
+// FindParsersForDirectory finds every parser that applies to the given directory.
+// Parsers sharing a group are alternatives (e.g., npm and yarn), so only the best
+// match of each group is returned, using the same rules as FindParserForDirectory.
+// The result is ordered by priority, a group ranking with its highest priority member, and then by name.
+func (p *ParsersFile) FindParsersForDirectory(directory string) ([]string, error) {
+	candidates := p.matchingParsers(directory)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no parser found for directory: %s", directory)
+	}
+
+	groups := make(map[string][]string)
+	var names []string
+	for _, name := range candidates {
+		group := p.Parsers[name].Group
+		if group == "" {
+			names = append(names, name)
+			continue
+		}
+		groups[group] = append(groups[group], name)
+	}
+
+	// Candidates are ordered by priority, so members[0] carries the group's priority
+	rank := make(map[string]int)
+	for _, members := range groups {
+		best := p.bestMatch(directory, members)
+		rank[best] = p.Parsers[members[0]].Priority
+		names = append(names, best)
+	}
+	for _, name := range names {
+		if _, ok := rank[name]; !ok {
+			rank[name] = p.Parsers[name].Priority
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if rank[names[i]] != rank[names[j]] {
+			return rank[names[i]] > rank[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names, nil
+}
+
+// bestMatch picks one of the ordered candidates for a directory
+func (p *ParsersFile) bestMatch(directory string, candidates []string) string {
+	if name, ok := p.matchPackageManager(directory, candidates); ok {
+		return name
+	}
+
+	if name, ok := p.matchLockFile(directory, candidates); ok {
+		return name
+	}
+
+	return candidates[0]
+}
+
+// matchingParsers returns the names of parsers whose detect files exist in the
+// directory, ordered by priority (highest first) and then by name
+func (p *ParsersFile) matchingParsers(directory string) []string {
+	var names []string
+	for name, parser := range p.Parsers {
+		for _, detectFile := range parser.DetectFiles {
+			if _, err := os.Stat(filepath.Join(directory, detectFile)); err == nil {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+
+	p.sortByPriority(names)
+	return names
+}
+
+// sortByPriority orders parser names by priority (highest first) and then by name
+func (p *ParsersFile) sortByPriority(names []string) {
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := p.Parsers[names[i]].Priority, p.Parsers[names[j]].Priority
+		if pi != pj {
+			return pi > pj
+		}
+		return names[i] < names[j]
+	})
+}
+
+// matchPackageManager picks the candidate named by the nearest package.json "packageManager" field
+func (p *ParsersFile) matchPackageManager(directory string, candidates []string) (string, bool) {
+	var manager string
+	walkToWorkspaceRoot(directory, func(dir string) bool {
+		manager = readPackageManager(filepath.Join(dir, "package.json"))
+		return manager != ""
+	})
+	if manager == "" {
+		return "", false
+	}
+
+	for _, name := range candidates {
+		if p.Parsers[name].PackageManager == manager {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// matchLockFile picks the candidate owning the nearest lock file
+func (p *ParsersFile) matchLockFile(directory string, candidates []string) (string, bool) {
+	var found string
+	walkToWorkspaceRoot(directory, func(dir string) bool {
+		// Candidates are already ordered, so priority decides between lock files in the same directory
+		for _, name := range candidates {
+			for _, lockFile := range p.Parsers[name].LockFiles {
+				if _, err := os.Stat(filepath.Join(dir, lockFile)); err == nil {
+					found = name
+					return true
+				}
+			}
+		}
+		return false
+	})
+	return found, found != ""
+}
+
+// readPackageManager returns the package manager name from a package.json
+// "packageManager" field (e.g., "pnpm" for "pnpm@8.15.0")
+func readPackageManager(packageJsonPath string) string {
+	data, err := os.ReadFile(packageJsonPath)
+	if err != nil {
+		return ""
+	}
+
+	var packageJson struct {
+		PackageManager string `json:"packageManager"`
+	}
+	if err := json.Unmarshal(data, &packageJson); err != nil {
+		return ""
+	}
+
+	name, _, _ := strings.Cut(packageJson.PackageManager, "@")
+	return strings.TrimSpace(name)
+}
+
+// walkToWorkspaceRoot calls visit for directory and each of its parents until visit
+// returns true, a workspace root (e.g., a directory containing .git) has been visited
+// or the filesystem root is reached.
+func walkToWorkspaceRoot(directory string, visit func(dir string) bool) {
+	current, err := filepath.Abs(directory)
+	if err != nil {
+		current = directory
+	}
+
+	for {
+		if visit(current) || isWorkspaceRoot(current) {
+			return
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return
+		}
+		current = parent
+	}
+}
+
+// isWorkspaceRoot reports whether the directory is the root of a repository
+func isWorkspaceRoot(directory string) bool {
+	for _, marker := range workspaceRootMarkers {
+		if _, err := os.Stat(filepath.Join(directory, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package parsers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files (and their directories) relative to root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for file, content := range files {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", file, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+}
+
+func TestFindParserForDirectory(t *testing.T) {
+	defaults, err := loadEmbeddedDefaults()
+	if err != nil {
+		t.Fatalf("Failed to load embedded defaults: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		dir      string
+		expected string
+	}{
+		{
+			name:     "plain package.json falls back to npm",
+			files:    map[string]string{"package.json": `{}`},
+			expected: "npm",
+		},
+		{
+			name:     "yarn lock file",
+			files:    map[string]string{"package.json": `{}`, "yarn.lock": ""},
+			expected: "yarn",
+		},
+		{
+			name:     "pnpm lock file",
+			files:    map[string]string{"package.json": `{}`, "pnpm-lock.yaml": ""},
+			expected: "pnpm",
+		},
+		{
+			name:     "bun lock file",
+			files:    map[string]string{"package.json": `{}`, "bun.lockb": ""},
+			expected: "bun",
+		},
+		{
+			name: "lock file at workspace root",
+			files: map[string]string{
+				".git/HEAD":                   "",
+				"pnpm-lock.yaml":              "",
+				"package.json":                `{}`,
+				"packages/web/package.json":   `{}`,
+				"packages/web/src/index.html": "",
+			},
+			dir:      "packages/web",
+			expected: "pnpm",
+		},
+		{
+			name: "nearest lock file wins",
+			files: map[string]string{
+				".git/HEAD":                 "",
+				"yarn.lock":                 "",
+				"packages/web/package.json": `{}`,
+				"packages/web/bun.lockb":    "",
+			},
+			dir:      "packages/web",
+			expected: "bun",
+		},
+		{
+			name: "lock file outside the workspace root is ignored",
+			files: map[string]string{
+				"yarn.lock":                      "",
+				"repo/.git/HEAD":                 "",
+				"repo/packages/web/package.json": `{}`,
+			},
+			dir:      "repo/packages/web",
+			expected: "npm",
+		},
+		{
+			name: "packageManager field beats lock files",
+			files: map[string]string{
+				"package.json": `{"packageManager": "pnpm@8.15.0"}`,
+				"yarn.lock":    "",
+			},
+			expected: "pnpm",
+		},
+		{
+			name: "packageManager field in workspace root",
+			files: map[string]string{
+				".git/HEAD":                 "",
+				"package.json":              `{"packageManager": "yarn@4.1.0"}`,
+				"packages/web/package.json": `{}`,
+			},
+			dir:      "packages/web",
+			expected: "yarn",
+		},
+		{
+			name:     "language parser beats make",
+			files:    map[string]string{"go.mod": "module example.com/x\n", "Makefile": "all:\n"},
+			expected: "go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+			dir := filepath.Join(root, tt.dir)
+
+			// Detection used to depend on map iteration order, make sure it is stable
+			for i := 0; i < 20; i++ {
+				name, _, err := defaults.FindParserForDirectory(dir)
+				if err != nil {
+					t.Fatalf("FindParserForDirectory() error = %v", err)
+				}
+				if name != tt.expected {
+					t.Fatalf("FindParserForDirectory() = %q, expected %q", name, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestFindParserForDirectoryPriority(t *testing.T) {
+	parsersFile := &ParsersFile{
+		Parsers: map[string]ParserConfig{
+			"alpha":  {DetectFiles: []string{"tasks.yml"}},
+			"beta":   {DetectFiles: []string{"tasks.yml"}, Priority: 5},
+			"gamma":  {DetectFiles: []string{"tasks.yml"}, Priority: 5},
+			"unused": {DetectFiles: []string{"other.yml"}, Priority: 100},
+		},
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"tasks.yml": ""})
+
+	name, _, err := parsersFile.FindParserForDirectory(dir)
+	if err != nil {
+		t.Fatalf("FindParserForDirectory() error = %v", err)
+	}
+	if name != "beta" {
+		t.Errorf("FindParserForDirectory() = %q, expected %q", name, "beta")
+	}
+
+	if _, _, err := parsersFile.FindParserForDirectory(t.TempDir()); err == nil {
+		t.Error("Expected error for directory without detect files")
+	}
+}
+
+func TestFindParsersForDirectory(t *testing.T) {
+	defaults, err := loadEmbeddedDefaults()
+	if err != nil {
+		t.Fatalf("Failed to load embedded defaults: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{
+			name:     "single type",
+			files:    map[string]string{"go.mod": "module example.com/x\n"},
+			expected: []string{"go"},
+		},
+		{
+			name:     "package managers of a group are alternatives",
+			files:    map[string]string{"package.json": `{}`, "yarn.lock": "", "Makefile": "all:\n"},
+			expected: []string{"yarn", "make"},
+		},
+		{
+			name: "several languages",
+			files: map[string]string{
+				"go.mod":       "module example.com/x\n",
+				"package.json": `{}`,
+				"Makefile":     "all:\n",
+			},
+			expected: []string{"go", "npm", "make"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			names, err := defaults.FindParsersForDirectory(dir)
+			if err != nil {
+				t.Fatalf("FindParsersForDirectory() error = %v", err)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("FindParsersForDirectory() = %v, expected %v", names, tt.expected)
+			}
+		})
+	}
+
+	if _, err := defaults.FindParsersForDirectory(t.TempDir()); err == nil {
+		t.Error("Expected error for directory without detect files")
+	}
+}
@@ -0,0 +1,106 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Formats of the output of a parser command, see ParserConfig.ParserOutput
+const (
+	OutputLines = "lines" // One key per line
+	OutputJSON  = "json"  // Command objects, as a JSON array or one per line
+	OutputJSONL = "jsonl" // Same as OutputJSON
+)
+
+// parseJSONOutput decodes the command objects written by a parser command in
+// json mode. The output is either a JSON array of objects or a stream of
+// objects (JSON Lines). Objects are validated, see validateParsedCommand.
+func parseJSONOutput(output []byte) ([]ParsedCommand, error) {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return []ParsedCommand{}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	decoder.DisallowUnknownFields()
+
+	var commands []ParsedCommand
+	if output[0] == '[' {
+		if err := decoder.Decode(&commands); err != nil {
+			return nil, fmt.Errorf("decoding command array: %w", err)
+		}
+		if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("unexpected data after the command array")
+		}
+	} else {
+		for {
+			var command ParsedCommand
+			err := decoder.Decode(&command)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("command %d: %w", len(commands)+1, err)
+			}
+			commands = append(commands, command)
+		}
+	}
+
+	seen := make(map[string]bool, len(commands))
+	for i, command := range commands {
+		if err := validateParsedCommand(command); err != nil {
+			return nil, fmt.Errorf("command %d: %w", i+1, err)
+		}
+		if seen[command.Key] {
+			return nil, fmt.Errorf("command %d: duplicate key %q", i+1, command.Key)
+		}
+		seen[command.Key] = true
+	}
+	return commands, nil
+}
+
+// validateParsedCommand checks a command object reported by a parser: the key
+// is required, cwd must stay inside the location and env names must be usable
+func validateParsedCommand(command ParsedCommand) error {
+	if strings.TrimSpace(command.Key) == "" {
+		return fmt.Errorf("missing key")
+	}
+	if strings.ContainsAny(command.Key, "\n\r") {
+		return fmt.Errorf("key %q spans several lines", command.Key)
+	}
+	if err := ValidateCwd(command.Cwd); err != nil {
+		return err
+	}
+	for name := range command.Env {
+		if name == "" || strings.ContainsAny(name, "=\x00") {
+			return fmt.Errorf("invalid env name %q", name)
+		}
+	}
+	for _, tag := range command.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("empty tag")
+		}
+	}
+	return nil
+}
+
+// ValidateCwd checks that the directory of a command, relative to its location,
+// stays inside the location. An empty cwd is the location itself. The config
+// file applies the same rule to the cwd of declared commands.
+func ValidateCwd(cwd string) error {
+	if cwd == "" {
+		return nil
+	}
+	if filepath.IsAbs(cwd) {
+		return fmt.Errorf("cwd %q must be relative to the location", cwd)
+	}
+	if clean := filepath.Clean(cwd); clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("cwd %q is outside the location", cwd)
+	}
+	return nil
+}
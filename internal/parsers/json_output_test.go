@@ -0,0 +1,156 @@
+package parsers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected []ParsedCommand
+		err      string
+	}{
+		{
+			name: "json lines",
+			output: `{"key": "build", "description": "Compile everything"}
+{"key": "e2e", "command": "./scripts/e2e.sh", "cwd": "web", "env": {"CI": "1"}, "tags": ["test", "slow"]}
+`,
+			expected: []ParsedCommand{
+				{Key: "build", Description: "Compile everything"},
+				{Key: "e2e", Command: "./scripts/e2e.sh", Cwd: "web", Env: map[string]string{"CI": "1"}, Tags: []string{"test", "slow"}},
+			},
+		},
+		{
+			name:     "array",
+			output:   `[{"key": "lint"}, {"key": "test"}]`,
+			expected: []ParsedCommand{{Key: "lint"}, {Key: "test"}},
+		},
+		{
+			name:   "data after the array",
+			output: `[{"key": "lint"}]]`,
+			err:    "unexpected data after the command array",
+		},
+		{
+			name:     "empty",
+			output:   "\n",
+			expected: []ParsedCommand{},
+		},
+		{
+			name:   "missing key",
+			output: `{"key": "build"}` + "\n" + `{"command": "make"}`,
+			err:    "command 2: missing key",
+		},
+		{
+			name:   "unknown field",
+			output: `{"key": "build", "descripton": "typo"}`,
+			err:    `command 1: json: unknown field "descripton"`,
+		},
+		{
+			name:   "duplicate key",
+			output: `[{"key": "build"}, {"key": "build"}]`,
+			err:    `command 2: duplicate key "build"`,
+		},
+		{
+			name:   "absolute cwd",
+			output: `{"key": "build", "cwd": "/tmp"}`,
+			err:    `cwd "/tmp" must be relative to the location`,
+		},
+		{
+			name:   "cwd outside the location",
+			output: `{"key": "build", "cwd": "web/../.."}`,
+			err:    `cwd "web/../.." is outside the location`,
+		},
+		{
+			name:   "env value not a string",
+			output: `{"key": "build", "env": {"PORT": 8080}}`,
+			err:    "command 1: json: cannot unmarshal number",
+		},
+		{
+			name:   "not json",
+			output: "build\ntest\n",
+			err:    "command 1: invalid character",
+		},
+		{
+			name:   "data after the array",
+			output: `[{"key": "build"}] {"key": "test"}`,
+			err:    "unexpected data after the command array",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands, err := parseJSONOutput([]byte(tt.output))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("parseJSONOutput() error = %v, expected %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseJSONOutput() error = %v", err)
+			}
+			if !reflect.DeepEqual(commands, tt.expected) {
+				t.Errorf("parseJSONOutput() = %+v, expected %+v", commands, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseCommandsWithSourceJSONOutput(t *testing.T) {
+	config := ParserConfig{
+		ParserCommand:   `printf '%s\n' '{"key": "build", "description": "Compile"}' '{"key": "e2e", "command": "./e2e.sh", "tags": ["test"]}'`,
+		ParserOutput:    OutputJSON,
+		CommandTemplate: "task {key}",
+	}
+
+	commands, err := ParseCommandsWithSource(context.Background(), ".", config)
+	if err != nil {
+		t.Fatalf("ParseCommandsWithSource() error = %v", err)
+	}
+
+	expected := []FormattedCommand{
+		{Key: "build", Command: "task build", Source: SourceParserCommand, Description: "Compile"},
+		{Key: "e2e", Command: "./e2e.sh", Source: SourceParserCommand, Tags: []string{"test"}},
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("ParseCommandsWithSource() = %+v, expected %+v", commands, expected)
+	}
+}
+
+func TestGetParserUnknownOutput(t *testing.T) {
+	_, err := GetParser(ParserConfig{ParserCommand: "task --list", ParserOutput: "xml"})
+	if err == nil || err.Error() != "unknown parser_output: xml" {
+		t.Errorf("GetParser() error = %v, expected an unknown parser_output error", err)
+	}
+}
+
+func TestValidateCwd(t *testing.T) {
+	tests := []struct {
+		cwd string
+		err string
+	}{
+		{cwd: ""},
+		{cwd: "web"},
+		{cwd: "web/../api"},
+		{cwd: "/srv/web", err: "must be relative to the location"},
+		{cwd: "..", err: "is outside the location"},
+		{cwd: "web/../../api", err: "is outside the location"},
+	}
+
+	for _, tt := range tests {
+		err := ValidateCwd(tt.cwd)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("ValidateCwd(%q) error = %v", tt.cwd, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ValidateCwd(%q) error = %v, expected %q", tt.cwd, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package parsers
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// makeIncludePattern matches the include directives of GNU make
+var makeIncludePattern = regexp.MustCompile(`^ *(?:-include|sinclude|include)\s+(?P<file>[^#]+)`)
+
+// MakefileParser lists the targets of a Makefile without running make: the
+// names of explicit rules and the prerequisites of .PHONY, in the order they
+// first appear. Included makefiles are read in place, relative to the project
+// directory like make does.
+//
+// A description is taken from a "## comment" after the rule:
+//
+//	build: deps ## Build the binary
+//
+// Pattern rules, targets built from variables and targets starting with "_" or
+// "." are hidden, as are those matching an entry of exclude_targets (a name or
+// a glob pattern).
+type MakefileParser struct{}
+
+func (m *MakefileParser) ParseCommands(ctx context.Context, directory string, config ParserConfig) ([]ParsedCommand, error) {
+	file := parserFile(directory, config)
+	if file == "" {
+		return []ParsedCommand{}, nil
+	}
+
+	commands := []ParsedCommand{}
+	index := make(map[string]int)
+	add := func(target, description string) {
+		if i, seen := index[target]; seen {
+			if commands[i].Description == "" {
+				commands[i].Description = description
+			}
+			return
+		}
+		if strings.ContainsAny(target, "$%") || hiddenTarget(target, config.ExcludeTargets) {
+			return
+		}
+		index[target] = len(commands)
+		commands = append(commands, ParsedCommand{Key: target, Description: description})
+	}
+
+	inDefine := false
+	_, err := scanTaskFiles(ctx, filepath.Join(directory, file), makeIncludePattern, directory, func(lines []string, i int) {
+		line := lines[i]
+		// Recipes and the bodies of multi-line variables never hold rules
+		if strings.HasPrefix(line, "\t") {
+			return
+		}
+		trimmed := strings.TrimSpace(line)
+		if inDefine {
+			inDefine = trimmed != "endef"
+			return
+		}
+		if trimmed == "define" || strings.HasPrefix(trimmed, "define ") {
+			inDefine = true
+			return
+		}
+		if i > 0 && strings.HasSuffix(lines[i-1], "\\") {
+			// Continuation lines are read with the line they continue
+			return
+		}
+
+		targets, prerequisites, description, ok := parseRule(joinContinuations(lines, i))
+		if !ok {
+			return
+		}
+		if len(targets) == 1 && targets[0] == ".PHONY" {
+			for _, target := range prerequisites {
+				add(target, "")
+			}
+			return
+		}
+		for _, target := range targets {
+			add(target, description)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// joinContinuations returns line i with the lines continuing it (after a trailing
+// backslash) appended
+func joinContinuations(lines []string, i int) string {
+	line := lines[i]
+	for strings.HasSuffix(line, "\\") && i+1 < len(lines) {
+		i++
+		line = strings.TrimSuffix(line, "\\") + " " + lines[i]
+	}
+	return line
+}
+
+// parseRule splits a rule line "targets: prerequisites ## description". It
+// reports false for lines that are not rules, such as variable assignments,
+// comments and pattern rules.
+func parseRule(line string) (targets, prerequisites []string, description string, ok bool) {
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return nil, nil, "", false
+	}
+
+	colon := strings.Index(line, ":")
+	if colon <= 0 {
+		return nil, nil, "", false
+	}
+	head, rest := line[:colon], line[colon+1:]
+	// X = a:b, X := y, X ::= y and conditionals are not rules
+	if strings.ContainsAny(head, "=#%$()") || strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, ":=") {
+		return nil, nil, "", false
+	}
+	rest = strings.TrimPrefix(rest, ":")
+
+	if i := strings.Index(rest, "##"); i >= 0 {
+		description = strings.TrimSpace(rest[i+2:])
+		rest = rest[:i]
+	}
+	if i := strings.Index(rest, "#"); i >= 0 {
+		rest = rest[:i]
+	}
+	if i := strings.Index(rest, ";"); i >= 0 {
+		rest = rest[:i]
+	}
+	// Target-specific variables (target: VAR = value) define no rule
+	if strings.Contains(rest, "=") {
+		return nil, nil, "", false
+	}
+
+	targets = strings.Fields(head)
+	if len(targets) == 0 {
+		return nil, nil, "", false
+	}
+	switch targets[0] {
+	case "ifeq", "ifneq", "ifdef", "ifndef", "else", "endif", "export", "unexport", "override", "vpath":
+		return nil, nil, "", false
+	}
+	return targets, strings.Fields(rest), description, true
+}
+
+// hiddenTarget reports whether a target is left out of the commands: special and
+// private targets (starting with "." or "_") and targets matching exclude_targets
+func hiddenTarget(target string, exclude []string) bool {
+	if strings.HasPrefix(target, ".") || strings.HasPrefix(target, "_") {
+		return true
+	}
+	for _, pattern := range exclude {
+		if matched, _ := path.Match(pattern, target); matched || pattern == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package parsers
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMakefileParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		config   ParserConfig
+		expected []ParsedCommand
+	}{
+		{
+			name: "rules, phony targets and descriptions",
+			files: map[string]string{
+				"Makefile": `# Build settings
+GO ?= go
+VERSION := $(shell git describe)
+LDFLAGS = -X main.version=$(VERSION)
+URL = http://example.com:8080
+.DEFAULT_GOAL := build
+.PHONY: build test \
+	lint release
+
+build: deps ## Build the binary
+	$(GO) build -ldflags "$(LDFLAGS)"
+
+test: ## Run the tests
+	$(GO) test ./...
+
+bin/app main.o: main.c
+	cc -o $@ $<
+
+%.o: %.c
+	cc -c $<
+
+$(OUT)/gen: gen.sh
+	./gen.sh
+
+_internal:
+	@echo hidden
+
+release: CGO_ENABLED = 0
+
+ifeq ($(OS),Windows_NT)
+windows: ## Only on Windows
+endif
+
+define HELP
+usage: make target
+endef
+
+clean::
+	rm -rf bin
+`,
+			},
+			config: ParserConfig{DetectFiles: []string{"Makefile", "makefile"}},
+			expected: []ParsedCommand{
+				{Key: "build", Description: "Build the binary"},
+				{Key: "test", Description: "Run the tests"},
+				{Key: "lint"},
+				{Key: "release"},
+				{Key: "bin/app"},
+				{Key: "main.o"},
+				{Key: "windows", Description: "Only on Windows"},
+				{Key: "clean"},
+			},
+		},
+		{
+			name: "includes relative to the project directory",
+			files: map[string]string{
+				"makefile": `include mk/common.mk
+-include mk/optional.mk $(LOCAL_MK)
+sinclude mk/*.ci.mk # CI targets
+all: ## Everything
+`,
+				"mk/common.mk":    "include mk/docker.mk\nfmt:\n",
+				"mk/docker.mk":    "docker-build: ## Build the image\n",
+				"mk/gitlab.ci.mk": "ci:\n",
+			},
+			config: ParserConfig{DetectFiles: []string{"Makefile", "makefile"}},
+			expected: []ParsedCommand{
+				{Key: "docker-build", Description: "Build the image"},
+				{Key: "fmt"},
+				{Key: "ci"},
+				{Key: "all", Description: "Everything"},
+			},
+		},
+		{
+			name: "excluded targets",
+			files: map[string]string{
+				"Makefile": "build:\ndebug-server:\ndebug-client:\ninstall:\n",
+			},
+			config:   ParserConfig{File: "Makefile", ExcludeTargets: []string{"install", "debug-*"}},
+			expected: []ParsedCommand{{Key: "build"}},
+		},
+		{
+			name:     "no makefile",
+			config:   ParserConfig{DetectFiles: []string{"Makefile"}},
+			expected: []ParsedCommand{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			parser := &MakefileParser{}
+			commands, err := parser.ParseCommands(context.Background(), dir, tt.config)
+			if err != nil {
+				t.Fatalf("ParseCommands() error = %v", err)
+			}
+			if !reflect.DeepEqual(commands, tt.expected) {
+				t.Errorf("ParseCommands() = %+v, expected %+v", commands, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		line          string
+		targets       []string
+		prerequisites []string
+		description   string
+		ok            bool
+	}{
+		{line: "build: deps gen ## Build it", targets: []string{"build"}, prerequisites: []string{"deps", "gen"}, description: "Build it", ok: true},
+		{line: "a b:: c # comment", targets: []string{"a", "b"}, prerequisites: []string{"c"}, ok: true},
+		{line: "run: ; ./run.sh", targets: []string{"run"}, prerequisites: []string{}, ok: true},
+		{line: "X := y"},
+		{line: "X ::= y"},
+		{line: "X = a:b"},
+		{line: "target: VAR := value"},
+		{line: "%.o: %.c"},
+		{line: "# build: commented out"},
+		{line: "export PATH:=bin"},
+		{line: "no colon here"},
+	}
+
+	for _, tt := range tests {
+		targets, prerequisites, description, ok := parseRule(tt.line)
+		if ok != tt.ok || !reflect.DeepEqual(targets, tt.targets) || !reflect.DeepEqual(prerequisites, tt.prerequisites) || description != tt.description {
+			t.Errorf("parseRule(%q) = %q, %q, %q, %v, expected %q, %q, %q, %v", tt.line,
+				targets, prerequisites, description, ok, tt.targets, tt.prerequisites, tt.description, tt.ok)
+		}
+	}
+}
+
+func TestInputFilesMakefile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Makefile": "include mk/common.mk\n",
+	})
+
+	config := ParserConfig{BuiltinParser: "makefile", DetectFiles: []string{"Makefile"}}
+	expected := []string{filepath.Join(dir, "Makefile"), filepath.Join(dir, "mk", "common.mk")}
+	if files := InputFiles(dir, config); !reflect.DeepEqual(files, expected) {
+		t.Errorf("InputFiles() = %q, expected %q", files, expected)
+	}
+}
@@ -1,15 +1,43 @@
 package parsers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
+// DefaultTimeout bounds a parser run when its configuration sets no timeout
+const DefaultTimeout = 30 * time.Second
+
 // Parser is the interface for parsing commands from a project
 type Parser interface {
-	// ParseCommands returns a list of commands from the project
-	ParseCommands(directory string, config ParserConfig) ([]string, error)
+	// ParseCommands returns a list of commands from the project. Parsers must
+	// give up and return ctx.Err() once ctx is done.
+	ParseCommands(ctx context.Context, directory string, config ParserConfig) ([]ParsedCommand, error)
+}
+
+// ParsedCommand is a command reported by a parser. Only Key is required, parsers
+// that know more about a command fill in the other fields.
+type ParsedCommand struct {
+	Key         string            `json:"key"`
+	Command     string            `json:"command,omitempty"`     // Full command line, the command template applied to Key when empty
+	Description string            `json:"description,omitempty"` // Shown in the preview
+	Cwd         string            `json:"cwd,omitempty"`         // Subdirectory of the location to run in
+	Env         map[string]string `json:"env,omitempty"`         // Added to the environment of the command
+	Tags        []string          `json:"tags,omitempty"`        // Free-form labels, e.g. for grouping
+}
+
+// keyCommands returns parsed commands that only have a key
+func keyCommands(keys []string) []ParsedCommand {
+	commands := make([]ParsedCommand, 0, len(keys))
+	for _, key := range keys {
+		commands = append(commands, ParsedCommand{Key: key})
+	}
+	return commands
 }
 
 // GetParser returns the appropriate parser based on the configuration
@@ -21,6 +49,12 @@ func GetParser(config ParserConfig) (Parser, error) {
 			return &PackageJsonParser{}, nil
 		case "go_standard":
 			return &GoStandardParser{}, nil
+		case "structured":
+			return &StructuredParser{}, nil
+		case "regex":
+			return &RegexParser{}, nil
+		case "makefile":
+			return &MakefileParser{}, nil
 		default:
 			return nil, fmt.Errorf("unknown built-in parser: %s", config.BuiltinParser)
 		}
@@ -28,7 +62,12 @@ func GetParser(config ParserConfig) (Parser, error) {
 
 	// If a parser command is specified, use the command parser
 	if config.ParserCommand != "" {
-		return &CommandParser{}, nil
+		switch config.ParserOutput {
+		case "", OutputLines, OutputJSON, OutputJSONL:
+			return &CommandParser{}, nil
+		default:
+			return nil, fmt.Errorf("unknown parser_output: %s", config.ParserOutput)
+		}
 	}
 
 	// If neither is specified, return a null parser (only base commands)
@@ -38,43 +77,166 @@ func GetParser(config ParserConfig) (Parser, error) {
 // NullParser returns no commands (used when only base commands are needed)
 type NullParser struct{}
 
-func (n *NullParser) ParseCommands(directory string, config ParserConfig) ([]string, error) {
-	return []string{}, nil
+func (n *NullParser) ParseCommands(ctx context.Context, directory string, config ParserConfig) ([]ParsedCommand, error) {
+	return []ParsedCommand{}, nil
+}
+
+// Sources reported for commands that were not produced by a builtin parser
+const (
+	SourceBaseCommands  = "base_commands"
+	SourceParserCommand = "parser_command"
+)
+
+// FormattedCommand is a parsed command together with where it came from
+type FormattedCommand struct {
+	Key     string // Key reported by the parser or defined in base_commands
+	Command string // Full command after applying the template
+	Source  string // Parser that produced the command
+
+	// Details reported by parsers that know them, see ParsedCommand
+	Description string
+	Cwd         string
+	Env         map[string]string
+	Tags        []string
 }
 
 // ParseAndFormatCommands parses commands and applies templates
 func ParseAndFormatCommands(directory string, config ParserConfig) (map[string]string, error) {
+	formatted, err := ParseCommandsWithSource(context.Background(), directory, config)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := make(map[string]string, len(formatted))
+	for _, cmd := range formatted {
+		commands[cmd.Key] = cmd.Command
+	}
+
+	return commands, nil
+}
+
+// ParseCommandsWithSource parses commands, applies templates and records which
+// parser produced each command. Base commands come first in the order they were
+// configured, followed by parsed commands in the order the parser reported them
+// (e.g., package.json script order). Parsed commands replace base commands with the same key.
+//
+// The parser runs under the configured timeout (DefaultTimeout when unset) and
+// stops early when ctx is cancelled.
+func ParseCommandsWithSource(ctx context.Context, directory string, config ParserConfig) ([]FormattedCommand, error) {
 	parser, err := GetParser(config)
 	if err != nil {
 		return nil, err
 	}
 
+	var commands []FormattedCommand
+	index := make(map[string]int)
+	add := func(cmd FormattedCommand) {
+		if i, exists := index[cmd.Key]; exists {
+			commands[i] = cmd
+			return
+		}
+		index[cmd.Key] = len(commands)
+		commands = append(commands, cmd)
+	}
+
 	// Start with base commands
-	commands := make(map[string]string)
-	for key, cmd := range config.BaseCommands {
-		commands[key] = cmd
+	for _, key := range config.BaseCommandKeys() {
+		add(FormattedCommand{Key: key, Command: config.BaseCommands[key], Source: SourceBaseCommands})
 	}
 
 	// Parse additional commands
-	parsedKeys, err := parser.ParseCommands(directory, config)
+	timeout := config.ParseTimeout()
+	parseCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	parsed, err := parser.ParseCommands(parseCtx, directory, config)
 	if err != nil {
+		if errors.Is(parseCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, fmt.Errorf("parser timed out after %s", timeout)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return nil, err
 	}
 
-	// Apply command template to parsed commands
-	for _, key := range parsedKeys {
-		if config.CommandTemplate != "" {
-			cmd := strings.ReplaceAll(config.CommandTemplate, "{key}", key)
-			commands[key] = cmd
-		} else {
-			// No template, use key as-is
-			commands[key] = key
+	// Apply command template to parsed commands that did not report a command line
+	source := ParserSource(config)
+	for _, parsedCmd := range parsed {
+		cmd := parsedCmd.Command
+		if cmd == "" {
+			cmd = parsedCmd.Key
+			if config.CommandTemplate != "" {
+				cmd = strings.ReplaceAll(config.CommandTemplate, "{key}", parsedCmd.Key)
+			}
 		}
+		add(FormattedCommand{
+			Key:         parsedCmd.Key,
+			Command:     cmd,
+			Source:      source,
+			Description: parsedCmd.Description,
+			Cwd:         parsedCmd.Cwd,
+			Env:         parsedCmd.Env,
+			Tags:        parsedCmd.Tags,
+		})
 	}
 
 	return commands, nil
 }
 
+// parserFile returns the file a builtin parser reads, relative to directory:
+// the configured file, or else the first detect file that exists
+func parserFile(directory string, config ParserConfig) string {
+	if config.File != "" {
+		return config.File
+	}
+	for _, detectFile := range config.DetectFiles {
+		if _, err := os.Stat(filepath.Join(directory, detectFile)); err == nil {
+			return detectFile
+		}
+	}
+	return ""
+}
+
+// InputFiles returns the files in directory whose contents the commands of a
+// parser depend on: the detect files, the file the parser reads and the files
+// it includes, see RegexParser. Files that do not exist are listed too, as
+// creating them changes the commands.
+func InputFiles(directory string, config ParserConfig) []string {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(file string) {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(directory, file)
+		}
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	for _, detectFile := range config.DetectFiles {
+		add(detectFile)
+	}
+	if config.File != "" {
+		add(config.File)
+	}
+	for _, file := range includedFiles(directory, config) {
+		add(file)
+	}
+	return files
+}
+
+// ParserSource returns the name of the parser used for a configuration
+func ParserSource(config ParserConfig) string {
+	if config.BuiltinParser != "" {
+		return config.BuiltinParser
+	}
+	if config.ParserCommand != "" {
+		return SourceParserCommand
+	}
+	return ""
+}
+
 // DetectAndParseCommands detects the project type and parses commands
 func DetectAndParseCommands(directory string, parsersConfig *ParsersFile) (map[string]string, error) {
 	// Find the appropriate parser for this directory
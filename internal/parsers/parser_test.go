@@ -1,7 +1,14 @@
 package parsers
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestGetParser(t *testing.T) {
@@ -112,7 +119,7 @@ func TestParseAndFormatCommands(t *testing.T) {
 func TestNullParser(t *testing.T) {
 	parser := &NullParser{}
 	
-	commands, err := parser.ParseCommands(".", ParserConfig{})
+	commands, err := parser.ParseCommands(context.Background(), ".", ParserConfig{})
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -120,4 +127,122 @@ func TestNullParser(t *testing.T) {
 	if len(commands) != 0 {
 		t.Errorf("Expected 0 commands from null parser, got %d", len(commands))
 	}
-}
\ No newline at end of file
+}
+
+func TestParseCommandsWithSource(t *testing.T) {
+	config := ParserConfig{
+		BaseCommands: map[string]string{
+			"install": "make deps",
+			"build":   "make all",
+		},
+		ParserCommand:   "printf 'build\\nlint\\n'",
+		CommandTemplate: "make {key}",
+	}
+
+	commands, err := ParseCommandsWithSource(context.Background(), ".", config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []FormattedCommand{
+		{Key: "build", Command: "make build", Source: SourceParserCommand},
+		{Key: "install", Command: "make deps", Source: SourceBaseCommands},
+		{Key: "lint", Command: "make lint", Source: SourceParserCommand},
+	}
+
+	if len(commands) != len(expected) {
+		t.Fatalf("Expected %d commands, got %d: %v", len(expected), len(commands), commands)
+	}
+	for i, cmd := range commands {
+		if !reflect.DeepEqual(cmd, expected[i]) {
+			t.Errorf("Command %d = %+v, expected %+v", i, cmd, expected[i])
+		}
+	}
+}
+
+func TestParseCommandsWithSourceOrder(t *testing.T) {
+	var parsersFile ParsersFile
+	err := yaml.Unmarshal([]byte(`parsers:
+  npm:
+    base_commands:
+      install: "npm install"
+      audit: "npm audit"
+      ci: "npm ci"
+    builtin_parser: "package_json_scripts"
+    command_template: "npm run {key}"
+`), &parsersFile)
+	if err != nil {
+		t.Fatalf("Failed to parse parsers config: %v", err)
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json": `{"scripts": {"start": "vite", "build": "vite build", "nested": {"x": 1}, "audit": "x", "lint": "eslint ."}}`,
+	})
+
+	// Run several times, map iteration used to shuffle the result
+	for i := 0; i < 10; i++ {
+		commands, err := ParseCommandsWithSource(context.Background(), dir, parsersFile.Parsers["npm"])
+		if err != nil {
+			t.Fatalf("ParseCommandsWithSource() error = %v", err)
+		}
+
+		var keys []string
+		for _, cmd := range commands {
+			keys = append(keys, cmd.Key)
+		}
+		expected := "install, audit, ci, start, build, lint"
+		if got := strings.Join(keys, ", "); got != expected {
+			t.Fatalf("ParseCommandsWithSource() keys = %s, expected %s", got, expected)
+		}
+	}
+}
+
+func TestParseCommandsWithSourceTimeout(t *testing.T) {
+	config := ParserConfig{
+		BaseCommands:  map[string]string{"install": "make deps"},
+		ParserCommand: "sleep 10; echo build",
+		Timeout:       100 * time.Millisecond,
+	}
+
+	start := time.Now()
+	_, err := ParseCommandsWithSource(context.Background(), ".", config)
+	if err == nil || err.Error() != "parser timed out after 100ms" {
+		t.Fatalf("ParseCommandsWithSource() error = %v, expected a timeout", err)
+	}
+	// The shell and the sleep it started are killed, not waited for
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ParseCommandsWithSource() returned after %s", elapsed)
+	}
+}
+
+func TestParseCommandsWithSourceCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	_, err := ParseCommandsWithSource(ctx, ".", ParserConfig{ParserCommand: "sleep 10"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ParseCommandsWithSource() error = %v, expected %v", err, context.Canceled)
+	}
+}
+
+func TestParserConfigTimeout(t *testing.T) {
+	var parsersFile ParsersFile
+	err := yaml.Unmarshal([]byte(`parsers:
+  gradle:
+    parser_command: "./gradlew tasks --all"
+    timeout: 2m
+  make:
+    parser_command: "make -qp"
+`), &parsersFile)
+	if err != nil {
+		t.Fatalf("Failed to parse parsers config: %v", err)
+	}
+
+	if got := parsersFile.Parsers["gradle"].ParseTimeout(); got != 2*time.Minute {
+		t.Errorf("gradle ParseTimeout() = %s, expected 2m", got)
+	}
+	if got := parsersFile.Parsers["make"].ParseTimeout(); got != DefaultTimeout {
+		t.Errorf("make ParseTimeout() = %s, expected %s", got, DefaultTimeout)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package parsers
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup kills the process when cmd is canceled. Process groups
+// are not available on this platform.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return cmd.Process.Kill()
+	}
+}
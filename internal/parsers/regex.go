@@ -0,0 +1,294 @@
+package parsers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Named groups of the patterns of RegexParser
+const (
+	groupKey         = "key"
+	groupDescription = "description"
+	groupFile        = "file"
+	groupNamespace   = "namespace"
+)
+
+// namespaceSeparator joins the namespaces of a key to its name, as rake does
+const namespaceSeparator = ":"
+
+// RegexParser lists the keys matched by a regular expression in a task file,
+// line by line, without running anything:
+//
+//	builtin_parser: regex
+//	file: justfile                     # defaults to the first detect file found
+//	pattern: '^(?P<key>[a-z][\w-]*)[^:]*:([^=]|$)'
+//	description_pattern: '^#\s*(?P<description>.+)'
+//	include_pattern: '^import\??\s+[''"](?P<file>[^''"]+)'
+//
+// The key group of pattern names the command and its optional description group
+// describes it, e.g. a "## comment" at the end of the line. When a line has no
+// description, description_pattern is tried on the line before it.
+//
+// Lines matching include_pattern name other files, in its file group, which are
+// scanned in place. Several files may be separated by spaces and may be glob
+// patterns. They are relative to the including file, and missing files are skipped.
+// Names holding make or shell variables ($) cannot be resolved and are skipped too.
+//
+// Lines matching namespace_pattern open a namespace, named by its namespace group,
+// that holds the following lines indented deeper than it. Keys inside are prefixed
+// by their namespaces and a colon, e.g. "db:migrate" for rake:
+//
+//	namespace :db do
+//	  task :migrate do
+type RegexParser struct{}
+
+func (r *RegexParser) ParseCommands(ctx context.Context, directory string, config ParserConfig) ([]ParsedCommand, error) {
+	patterns, err := compileRegexPatterns(config)
+	if err != nil {
+		return nil, err
+	}
+
+	file := parserFile(directory, config)
+	if file == "" {
+		return []ParsedCommand{}, nil
+	}
+
+	commands := []ParsedCommand{}
+	seen := make(map[string]bool)
+	var namespaces namespaceStack
+	_, err = scanTaskFiles(ctx, filepath.Join(directory, file), patterns.include, "", func(lines []string, i int) {
+		if patterns.namespace != nil && strings.TrimSpace(lines[i]) != "" {
+			indent := lineIndent(lines[i])
+			namespaces.close(indent)
+			if match := patterns.namespace.FindStringSubmatch(lines[i]); match != nil {
+				namespaces.open(indent, strings.TrimSpace(match[patterns.namespace.SubexpIndex(groupNamespace)]))
+				return
+			}
+		}
+
+		match := patterns.key.FindStringSubmatch(lines[i])
+		if match == nil {
+			return
+		}
+		key := strings.TrimSpace(match[patterns.key.SubexpIndex(groupKey)])
+		if key == "" {
+			return
+		}
+		key = namespaces.prefix() + key
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		command := ParsedCommand{Key: key}
+		if index := patterns.key.SubexpIndex(groupDescription); index >= 0 {
+			command.Description = strings.TrimSpace(match[index])
+		}
+		if command.Description == "" && patterns.description != nil && i > 0 {
+			if match := patterns.description.FindStringSubmatch(lines[i-1]); match != nil {
+				command.Description = strings.TrimSpace(match[patterns.description.SubexpIndex(groupDescription)])
+			}
+		}
+		commands = append(commands, command)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// namespaceStack holds the namespaces opened around the current line, innermost last
+type namespaceStack struct {
+	indents []int
+	names   []string
+}
+
+// open opens a namespace at a line indented by indent
+func (s *namespaceStack) open(indent int, name string) {
+	s.indents = append(s.indents, indent)
+	s.names = append(s.names, name)
+}
+
+// close closes the namespaces that do not hold a line indented by indent
+func (s *namespaceStack) close(indent int) {
+	for len(s.indents) > 0 && s.indents[len(s.indents)-1] >= indent {
+		s.indents = s.indents[:len(s.indents)-1]
+		s.names = s.names[:len(s.names)-1]
+	}
+}
+
+// prefix is the prefix of keys in the open namespaces, "" outside of them
+func (s *namespaceStack) prefix() string {
+	if len(s.names) == 0 {
+		return ""
+	}
+	return strings.Join(s.names, namespaceSeparator) + namespaceSeparator
+}
+
+// lineIndent is the number of spaces and tabs a line starts with
+func lineIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// regexPatterns are the compiled patterns of a regex parser configuration
+type regexPatterns struct {
+	key         *regexp.Regexp
+	description *regexp.Regexp
+	include     *regexp.Regexp
+	namespace   *regexp.Regexp
+}
+
+// compileRegexPatterns compiles the patterns of a regex parser and checks they
+// have the groups they need
+func compileRegexPatterns(config ParserConfig) (regexPatterns, error) {
+	var patterns regexPatterns
+	if config.Pattern == "" {
+		return patterns, fmt.Errorf("regex parser needs a pattern")
+	}
+
+	var err error
+	if patterns.key, err = compileWithGroup("pattern", config.Pattern, groupKey); err != nil {
+		return patterns, err
+	}
+	if config.DescriptionPattern != "" {
+		if patterns.description, err = compileWithGroup("description_pattern", config.DescriptionPattern, groupDescription); err != nil {
+			return patterns, err
+		}
+	}
+	if config.NamespacePattern != "" {
+		if patterns.namespace, err = compileWithGroup("namespace_pattern", config.NamespacePattern, groupNamespace); err != nil {
+			return patterns, err
+		}
+	}
+	if patterns.include, err = compileIncludePattern(config); err != nil {
+		return patterns, err
+	}
+	return patterns, nil
+}
+
+// compileIncludePattern compiles the include pattern of a configuration, nil when it has none
+func compileIncludePattern(config ParserConfig) (*regexp.Regexp, error) {
+	if config.IncludePattern == "" {
+		return nil, nil
+	}
+	return compileWithGroup("include_pattern", config.IncludePattern, groupFile)
+}
+
+// compileWithGroup compiles the pattern of an option and checks it has a named group
+func compileWithGroup(option, pattern, group string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", option, err)
+	}
+	if re.SubexpIndex(group) < 0 {
+		return nil, fmt.Errorf("%s %q has no (?P<%s>...) group", option, pattern, group)
+	}
+	return re, nil
+}
+
+// scanTaskFiles calls visit for every line of a file and of the files it includes,
+// in the order make would read them: an included file is scanned at the line that
+// includes it. Lines including files are not visited. Each file is scanned once.
+// Included files are relative to includeDir, or to the including file when it is
+// empty. It returns every file it looked at, including those found missing.
+func scanTaskFiles(ctx context.Context, path string, include *regexp.Regexp, includeDir string, visit func(lines []string, i int)) ([]string, error) {
+	var files []string
+	scanned := make(map[string]bool)
+
+	var scan func(path string) error
+	scan = func(path string) error {
+		if scanned[path] {
+			return nil
+		}
+		scanned[path] = true
+		files = append(files, path)
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+		for i, line := range lines {
+			if include != nil {
+				if match := include.FindStringSubmatch(line); match != nil {
+					dir := includeDir
+					if dir == "" {
+						dir = filepath.Dir(path)
+					}
+					for _, included := range resolveIncludes(match[include.SubexpIndex(groupFile)], dir) {
+						if err := scan(included); err != nil {
+							return err
+						}
+					}
+					continue
+				}
+			}
+			visit(lines, i)
+		}
+		return nil
+	}
+
+	err := scan(path)
+	return files, err
+}
+
+// resolveIncludes returns the files named by the file group of an include line,
+// relative to dir. Glob patterns are expanded in sorted order.
+func resolveIncludes(names string, dir string) []string {
+	var files []string
+	for _, name := range strings.Fields(names) {
+		if strings.Contains(name, "$") {
+			continue
+		}
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		if !strings.ContainsAny(name, "*?[") {
+			files = append(files, name)
+			continue
+		}
+		matches, err := filepath.Glob(name)
+		if err != nil {
+			continue
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files
+}
+
+// includedFiles returns the files a parser reads through includes, see InputFiles
+func includedFiles(directory string, config ParserConfig) []string {
+	var include *regexp.Regexp
+	includeDir := ""
+	switch config.BuiltinParser {
+	case "regex":
+		include, _ = compileIncludePattern(config)
+	case "makefile":
+		include, includeDir = makeIncludePattern, directory
+	}
+	if include == nil {
+		return nil
+	}
+	file := parserFile(directory, config)
+	if file == "" {
+		return nil
+	}
+
+	files, _ := scanTaskFiles(context.Background(), filepath.Join(directory, file), include, includeDir, func([]string, int) {})
+	return files
+}
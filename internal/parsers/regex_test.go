@@ -0,0 +1,235 @@
+package parsers
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegexParser(t *testing.T) {
+	makefileConfig := ParserConfig{
+		File:               "Makefile",
+		Pattern:            `^(?P<key>[a-zA-Z][\w-]*):[^=]*?(##\s*(?P<description>.*))?$`,
+		DescriptionPattern: `^##\s*(?P<description>.+)`,
+		IncludePattern:     `^-?include\s+(?P<file>.+)`,
+	}
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		config   ParserConfig
+		expected []ParsedCommand
+		err      string
+	}{
+		{
+			name: "descriptions on the same and previous line",
+			files: map[string]string{
+				"Makefile": `VERSION := 1.0
+
+build: deps ## Build the binary
+	go build
+
+## Run the tests
+test:
+	go test ./...
+
+build:
+	@echo duplicate
+`,
+			},
+			config: makefileConfig,
+			expected: []ParsedCommand{
+				{Key: "build", Description: "Build the binary"},
+				{Key: "test", Description: "Run the tests"},
+			},
+		},
+		{
+			name: "includes are scanned in place",
+			files: map[string]string{
+				"Makefile": `first:
+include mk/*.mk missing.mk
+-include Makefile
+last:
+`,
+				"mk/b.mk":          "from-b: ## Second file\n",
+				"mk/a.mk":          "include ../shared/common.mk\nfrom-a:\n",
+				"shared/common.mk": "common:\n",
+			},
+			config: makefileConfig,
+			expected: []ParsedCommand{
+				{Key: "first"},
+				{Key: "common"},
+				{Key: "from-a"},
+				{Key: "from-b", Description: "Second file"},
+				{Key: "last"},
+			},
+		},
+		{
+			name: "includes are not followed without an include pattern",
+			files: map[string]string{
+				"Makefile": "include other.mk\nlocal:\n",
+				"other.mk": "other:\n",
+			},
+			config:   ParserConfig{File: "Makefile", Pattern: makefileConfig.Pattern},
+			expected: []ParsedCommand{{Key: "local"}},
+		},
+		{
+			name:     "file from detect files",
+			files:    map[string]string{"Justfile": "# Serve the site\nserve port='8080':\n  hugo serve\n"},
+			config:   ParserConfig{DetectFiles: []string{"justfile", "Justfile"}, Pattern: `^(?P<key>[a-z]+)[^:]*:`, DescriptionPattern: `^#\s*(?P<description>.+)`},
+			expected: []ParsedCommand{{Key: "serve", Description: "Serve the site"}},
+		},
+		{
+			name:   "pattern without a key group",
+			config: ParserConfig{File: "Makefile", Pattern: `^(\w+):`},
+			err:    "has no (?P<key>...) group",
+		},
+		{
+			name:   "invalid include pattern",
+			config: ParserConfig{File: "Makefile", Pattern: makefileConfig.Pattern, IncludePattern: `^include (`},
+			err:    "invalid include_pattern",
+		},
+		{
+			name:   "namespace pattern without a namespace group",
+			config: ParserConfig{File: "Rakefile", Pattern: `^task :(?P<key>\w+)`, NamespacePattern: `^namespace :(\w+)`},
+			err:    "has no (?P<namespace>...) group",
+		},
+		{
+			name:   "no pattern",
+			config: ParserConfig{File: "Makefile"},
+			err:    "regex parser needs a pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			parser := &RegexParser{}
+			commands, err := parser.ParseCommands(context.Background(), dir, tt.config)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ParseCommands() error = %v, expected %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCommands() error = %v", err)
+			}
+			if !reflect.DeepEqual(commands, tt.expected) {
+				t.Errorf("ParseCommands() = %+v, expected %+v", commands, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultRegexParsers(t *testing.T) {
+	defaults, err := loadEmbeddedDefaults()
+	if err != nil {
+		t.Fatalf("Failed to load embedded defaults: %v", err)
+	}
+
+	tests := []struct {
+		parser   string
+		files    map[string]string
+		expected []FormattedCommand
+	}{
+		{
+			parser: "just",
+			files: map[string]string{
+				"justfile": `set shell := ["bash", "-c"]
+alias b := build
+version := "1.0"
+
+import? 'ci.just'
+
+# Build everything
+build target='all':
+    make {{target}}
+
+_helper:
+    echo private
+`,
+				"ci.just": "# Run in CI\nci: build\n",
+			},
+			expected: []FormattedCommand{
+				{Key: "ci", Command: "just ci", Source: "regex", Description: "Run in CI"},
+				{Key: "build", Command: "just build", Source: "regex", Description: "Build everything"},
+			},
+		},
+		{
+			parser: "rake",
+			files: map[string]string{
+				"Rakefile": `desc "Run the specs"
+task :spec do
+  sh "rspec"
+end
+
+namespace :db do
+  desc "Migrate the database"
+  task :migrate => :environment do
+    sh "rails db:migrate"
+  end
+
+  namespace :seed do
+    task :replant
+  end
+
+  task :reset
+end
+
+task default: [:spec]
+`,
+			},
+			expected: []FormattedCommand{
+				{Key: "spec", Command: "rake spec", Source: "regex", Description: "Run the specs"},
+				{Key: "db:migrate", Command: "rake db:migrate", Source: "regex", Description: "Migrate the database"},
+				{Key: "db:seed:replant", Command: "rake db:seed:replant", Source: "regex"},
+				{Key: "db:reset", Command: "rake db:reset", Source: "regex"},
+				{Key: "default", Command: "rake default", Source: "regex"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.parser, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			commands, err := ParseCommandsWithSource(context.Background(), dir, defaults.Parsers[tt.parser])
+			if err != nil {
+				t.Fatalf("ParseCommandsWithSource() error = %v", err)
+			}
+			if !reflect.DeepEqual(commands, tt.expected) {
+				t.Errorf("ParseCommandsWithSource() = %+v, expected %+v", commands, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInputFilesFollowsIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Makefile":  "include common.mk optional.mk\n",
+		"common.mk": "lint:\n",
+	})
+
+	config := ParserConfig{
+		BuiltinParser:  "regex",
+		DetectFiles:    []string{"Makefile", "makefile"},
+		Pattern:        `^(?P<key>\w+):`,
+		IncludePattern: `^-?include\s+(?P<file>.+)`,
+	}
+	expected := []string{
+		filepath.Join(dir, "Makefile"),
+		filepath.Join(dir, "makefile"),
+		filepath.Join(dir, "common.mk"),
+		filepath.Join(dir, "optional.mk"),
+	}
+	if files := InputFiles(dir, config); !reflect.DeepEqual(files, expected) {
+		t.Errorf("InputFiles() = %q, expected %q", files, expected)
+	}
+}
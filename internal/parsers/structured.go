@@ -0,0 +1,432 @@
+package parsers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of the files read by StructuredParser
+const (
+	FormatJSON  = "json"  // JSON, comments and trailing commas allowed
+	FormatJSONC = "jsonc" // Same as FormatJSON
+	FormatYAML  = "yaml"
+	FormatTOML  = "toml"
+)
+
+// StructuredParser lists the keys of a map inside a JSON, YAML or TOML file, such
+// as "scripts" in composer.json or "tasks" in deno.json. The file, its format and
+// the path of the map come from the parser configuration:
+//
+//	builtin_parser: structured
+//	file: deno.jsonc            # defaults to the first detect file found
+//	format: jsonc               # defaults to the file extension
+//	path: .tasks                # dotted path, e.g. tool.pdm.scripts
+//	description_field: description
+//
+// Descriptions come from a field of each entry (description_field), for entries
+// that are objects, or from a sibling map of key to description (descriptions,
+// e.g. "scripts-descriptions" in composer.json). Keys are listed in file order.
+type StructuredParser struct{}
+
+func (s *StructuredParser) ParseCommands(ctx context.Context, directory string, config ParserConfig) ([]ParsedCommand, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	file := parserFile(directory, config)
+	if file == "" {
+		return []ParsedCommand{}, nil
+	}
+	data, err := os.ReadFile(filepath.Join(directory, file))
+	if errors.Is(err, fs.ErrNotExist) {
+		// The file is optional when several detect files identify the project
+		return []ParsedCommand{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	format := config.Format
+	if format == "" {
+		format = formatFromExtension(file)
+	}
+	root, err := decodeStructured(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	entries, err := lookupMap(root, config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if entries == nil {
+		return []ParsedCommand{}, nil
+	}
+
+	var descriptions *orderedMap
+	if config.Descriptions != "" {
+		if descriptions, err = lookupMap(root, config.Descriptions); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	commands := make([]ParsedCommand, 0, len(entries.keys))
+	for _, key := range entries.keys {
+		command := ParsedCommand{Key: key}
+		if fields, ok := entries.values[key].(*orderedMap); ok && config.DescriptionField != "" {
+			command.Description, _ = fields.values[config.DescriptionField].(string)
+		}
+		if descriptions != nil && command.Description == "" {
+			command.Description, _ = descriptions.values[key].(string)
+		}
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
+// formatFromExtension guesses the format of a file from its extension
+func formatFromExtension(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return FormatJSON
+	case ".jsonc":
+		return FormatJSONC
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	}
+	return ""
+}
+
+// orderedMap is a decoded object or table that remembers the order of its keys.
+// Values are *orderedMap, []any or scalars.
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) set(key string, value any) {
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: make(map[string]any)}
+}
+
+// decodeStructured decodes a document in one of the structured formats
+func decodeStructured(data []byte, format string) (any, error) {
+	switch format {
+	case FormatJSON, FormatJSONC:
+		decoder := json.NewDecoder(bytes.NewReader(stripJSONC(data)))
+		value, err := decodeJSONValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("unexpected data after the document")
+		}
+		return value, nil
+	case FormatYAML:
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, err
+		}
+		return yamlValue(&node), nil
+	case FormatTOML:
+		var table map[string]any
+		metadata, err := toml.Decode(string(data), &table)
+		if err != nil {
+			return nil, err
+		}
+		return tomlValue(table, tomlKeyOrder(metadata), nil), nil
+	case "":
+		return nil, fmt.Errorf("unknown format, set format: to json, jsonc, yaml or toml")
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// decodeJSONValue decodes the next JSON value, keeping the order of object keys
+func decodeJSONValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := newOrderedMap()
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyToken.(string)
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.set(key, value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return object, nil
+	case json.Delim('['):
+		var array []any
+		for decoder.More() {
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return array, nil
+	}
+	return token, nil
+}
+
+// yamlValue converts a YAML node, keeping the order of mapping keys
+func yamlValue(node *yaml.Node) any {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.MappingNode:
+		mapping := newOrderedMap()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			mapping.set(node.Content[i].Value, yamlValue(node.Content[i+1]))
+		}
+		return mapping
+	case yaml.SequenceNode:
+		sequence := make([]any, 0, len(node.Content))
+		for _, item := range node.Content {
+			sequence = append(sequence, yamlValue(item))
+		}
+		return sequence
+	}
+	return node.Value
+}
+
+// tomlKeyOrder maps the path of every TOML table, joined by tomlPath, to its
+// keys in file order. The tables of an array of tables share the path of the array.
+func tomlKeyOrder(metadata toml.MetaData) map[string][]string {
+	order := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, key := range metadata.Keys() {
+		// Parent tables may be implied by dotted keys and table headers
+		for i := range key {
+			parent := tomlPath(key[:i])
+			if child := parent + "\x00" + key[i]; !seen[child] {
+				seen[child] = true
+				order[parent] = append(order[parent], key[i])
+			}
+		}
+	}
+	return order
+}
+
+// tomlPath joins the keys of a TOML path
+func tomlPath(keys []string) string {
+	return strings.Join(keys, "\x00")
+}
+
+// tomlValue converts a decoded TOML value at path, keeping the order of table
+// keys given by tomlKeyOrder. Keys missing from order come last, sorted.
+func tomlValue(value any, order map[string][]string, path []string) any {
+	switch value := value.(type) {
+	case map[string]any:
+		table := newOrderedMap()
+		for _, key := range order[tomlPath(path)] {
+			if item, ok := value[key]; ok {
+				table.set(key, tomlValue(item, order, append(path[:len(path):len(path)], key)))
+			}
+		}
+
+		var rest []string
+		for key := range value {
+			if _, ok := table.values[key]; !ok {
+				rest = append(rest, key)
+			}
+		}
+		sort.Strings(rest)
+		for _, key := range rest {
+			table.set(key, tomlValue(value[key], order, append(path[:len(path):len(path)], key)))
+		}
+		return table
+	case []any:
+		array := make([]any, 0, len(value))
+		for _, item := range value {
+			array = append(array, tomlValue(item, order, path))
+		}
+		return array
+	case []map[string]any:
+		// Arrays of tables
+		array := make([]any, 0, len(value))
+		for _, item := range value {
+			array = append(array, tomlValue(item, order, path))
+		}
+		return array
+	}
+	return value
+}
+
+// lookupMap returns the map at a dotted path such as ".scripts" or
+// `tool.pdm.scripts`, or nil when the path does not exist. Segments holding
+// dots are quoted: `."my.tasks"`.
+func lookupMap(root any, path string) (*orderedMap, error) {
+	segments, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value := root
+	for i, segment := range segments {
+		object, ok := value.(*orderedMap)
+		if !ok {
+			return nil, fmt.Errorf("path %s: %s is not a map", path, strings.Join(segments[:i], "."))
+		}
+		if value, ok = object.values[segment]; !ok {
+			return nil, nil
+		}
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+	object, ok := value.(*orderedMap)
+	if !ok {
+		return nil, fmt.Errorf("path %s is not a map", path)
+	}
+	return object, nil
+}
+
+// splitPath splits a dotted path into its keys. A leading dot is optional.
+func splitPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	var segments []string
+	for {
+		var segment string
+		if strings.HasPrefix(path, `"`) {
+			end := strings.Index(path[1:], `"`)
+			if end < 0 {
+				return nil, fmt.Errorf("path %s: unterminated quote", path)
+			}
+			segment, path = path[1:end+1], path[end+2:]
+		} else {
+			end := strings.IndexAny(path, `."`)
+			if end < 0 {
+				end = len(path)
+			}
+			segment, path = path[:end], path[end:]
+		}
+		if segment == "" {
+			return nil, fmt.Errorf("path has an empty key")
+		}
+		segments = append(segments, segment)
+
+		if path == "" {
+			return segments, nil
+		}
+		if path[0] != '.' {
+			return nil, fmt.Errorf("path: expected . before %s", path)
+		}
+		path = path[1:]
+	}
+}
+
+// stripJSONC turns JSON with comments (JSONC) into JSON: line and block comments
+// are replaced by spaces and trailing commas before } and ] are dropped
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				i = len(data)
+			} else {
+				i += end + 3
+			}
+			out = append(out, ' ')
+		case c == ',' && closesNext(data[i+1:]):
+			out = append(out, ' ')
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// closesNext reports whether the next token in data, skipping whitespace and
+// comments, closes an object or array
+func closesNext(data []byte) bool {
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return false
+			}
+			i += end + 3
+		default:
+			return c == '}' || c == ']'
+		}
+	}
+	return false
+}
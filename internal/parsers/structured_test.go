@@ -0,0 +1,217 @@
+package parsers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStructuredParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		config   ParserConfig
+		expected []ParsedCommand
+		err      string
+	}{
+		{
+			name: "deno tasks from jsonc with descriptions",
+			files: map[string]string{
+				"deno.jsonc": `{
+  // Tasks run with deno task
+  "tasks": {
+    "dev": "deno run --watch main.ts", /* the usual one */
+    "build": {
+      "command": "deno compile main.ts",
+      "description": "Compile a binary", // trailing comma next
+    },
+    "url": "echo http://example.com//not-a-comment",
+  },
+}`,
+			},
+			config: ParserConfig{DetectFiles: []string{"deno.json", "deno.jsonc"}, Path: ".tasks", DescriptionField: "description"},
+			expected: []ParsedCommand{
+				{Key: "dev"},
+				{Key: "build", Description: "Compile a binary"},
+				{Key: "url"},
+			},
+		},
+		{
+			name: "composer scripts with sibling descriptions",
+			files: map[string]string{
+				"composer.json": `{"scripts": {"test": "phpunit", "lint": "phpcs"}, "scripts-descriptions": {"test": "Run the tests"}}`,
+			},
+			config:   ParserConfig{File: "composer.json", Path: "scripts", Descriptions: "scripts-descriptions"},
+			expected: []ParsedCommand{{Key: "test", Description: "Run the tests"}, {Key: "lint"}},
+		},
+		{
+			name: "pdm scripts from toml",
+			files: map[string]string{
+				"pyproject.toml": `[project]
+name = "app"
+
+[tool.pdm.scripts]
+start = "flask run"
+test = { cmd = "pytest", help = "Run the tests" }
+`,
+			},
+			config:   ParserConfig{File: "pyproject.toml", Path: "tool.pdm.scripts", DescriptionField: "help"},
+			expected: []ParsedCommand{{Key: "start"}, {Key: "test", Description: "Run the tests"}},
+		},
+		{
+			name: "toml keeps file order",
+			files: map[string]string{
+				"pyproject.toml": `[tool.pdm.scripts]
+zeta = "echo z"
+lint.cmd = "ruff check"
+alpha = { cmd = "echo a", help = "First letter" }
+
+[tool.pdm.scripts.build]
+help = "Build the wheel"
+cmd = "pdm build"
+`,
+			},
+			config: ParserConfig{File: "pyproject.toml", Path: "tool.pdm.scripts", DescriptionField: "help"},
+			expected: []ParsedCommand{
+				{Key: "zeta"},
+				{Key: "lint"},
+				{Key: "alpha", Description: "First letter"},
+				{Key: "build", Description: "Build the wheel"},
+			},
+		},
+		{
+			name: "yaml keeps file order",
+			files: map[string]string{
+				"Taskfile.yml": `version: "3"
+tasks:
+  zeta:
+    desc: Last letter
+    cmds: [echo z]
+  alpha:
+    cmds: [echo a]
+`,
+			},
+			config:   ParserConfig{File: "Taskfile.yml", Path: "tasks", DescriptionField: "desc"},
+			expected: []ParsedCommand{{Key: "zeta", Description: "Last letter"}, {Key: "alpha"}},
+		},
+		{
+			name: "quoted path segment",
+			files: map[string]string{
+				"tasks.json": `{"my.tasks": {"one": "1"}}`,
+			},
+			config:   ParserConfig{File: "tasks.json", Path: `."my.tasks"`},
+			expected: []ParsedCommand{{Key: "one"}},
+		},
+		{
+			name:     "missing path",
+			files:    map[string]string{"composer.json": `{"name": "app"}`},
+			config:   ParserConfig{File: "composer.json", Path: "scripts"},
+			expected: []ParsedCommand{},
+		},
+		{
+			name:     "missing file",
+			config:   ParserConfig{File: "pyproject.toml", Path: "project.scripts"},
+			expected: []ParsedCommand{},
+		},
+		{
+			name:   "path through a value",
+			files:  map[string]string{"composer.json": `{"name": "app"}`},
+			config: ParserConfig{File: "composer.json", Path: "name.scripts"},
+			err:    "path name.scripts: name is not a map",
+		},
+		{
+			name:   "unknown format",
+			files:  map[string]string{"tasks.ini": "[tasks]"},
+			config: ParserConfig{File: "tasks.ini", Path: "tasks"},
+			err:    "unknown format",
+		},
+		{
+			name:   "trailing garbage",
+			files:  map[string]string{"deno.json": `{"tasks": {"dev": "deno run main.ts"}}x`},
+			config: ParserConfig{File: "deno.json", Path: "tasks"},
+			err:    "unexpected data after the document",
+		},
+		{
+			name:   "trailing bracket",
+			files:  map[string]string{"deno.json": `{"tasks": {"dev": "deno run main.ts"}}]`},
+			config: ParserConfig{File: "deno.json", Path: "tasks"},
+			err:    "unexpected data after the document",
+		},
+		{
+			name:   "invalid document",
+			files:  map[string]string{"deno.json": `{"tasks": {`},
+			config: ParserConfig{File: "deno.json", Path: "tasks"},
+			err:    "failed to parse deno.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			parser := &StructuredParser{}
+			commands, err := parser.ParseCommands(context.Background(), dir, tt.config)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ParseCommands() error = %v, expected %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCommands() error = %v", err)
+			}
+			if !reflect.DeepEqual(commands, tt.expected) {
+				t.Errorf("ParseCommands() = %+v, expected %+v", commands, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"a": 1} // comment`, `{"a": 1} `},
+		{`{"a": "//", "b": "/*"}`, `{"a": "//", "b": "/*"}`},
+		{`{"a": "\"//"}`, `{"a": "\"//"}`},
+		{`[1, 2, /* c */ ]`, `[1, 2    ]`},
+		{"{\"a\": 1,\n// last\n}", "{\"a\": 1 \n\n}"},
+	}
+
+	for _, tt := range tests {
+		if got := string(stripJSONC([]byte(tt.input))); got != tt.expected {
+			t.Errorf("stripJSONC(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []string
+		err      bool
+	}{
+		{path: ".scripts", expected: []string{"scripts"}},
+		{path: "tool.pdm.scripts", expected: []string{"tool", "pdm", "scripts"}},
+		{path: `tool."a.b".c`, expected: []string{"tool", "a.b", "c"}},
+		{path: ".", expected: nil},
+		{path: "a..b", err: true},
+		{path: `"open`, err: true},
+	}
+
+	for _, tt := range tests {
+		segments, err := splitPath(tt.path)
+		if tt.err {
+			if err == nil {
+				t.Errorf("splitPath(%q) expected an error, got %q", tt.path, segments)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(segments, tt.expected) {
+			t.Errorf("splitPath(%q) = %q, %v, expected %q", tt.path, segments, err, tt.expected)
+		}
+	}
+}
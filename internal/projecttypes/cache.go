@@ -0,0 +1,57 @@
+package projecttypes
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/martin/go-pm/internal/cache"
+	"github.com/martin/go-pm/internal/parsers"
+)
+
+// commandCache stores the commands parsed for a directory between runs, nil
+// when caching is off
+var (
+	commandCache      *cache.Cache
+	commandCacheMutex sync.RWMutex
+)
+
+// SetCommandCache makes configurable project types keep their parsed commands
+// in c. Passing nil turns caching off, which is the default.
+func SetCommandCache(c *cache.Cache) {
+	commandCacheMutex.Lock()
+	defer commandCacheMutex.Unlock()
+	commandCache = c
+}
+
+// cachedCommands returns the commands of a parser for a directory from the cache,
+// or parses and stores them. Entries are keyed by the parser, its configuration
+// and the directory, and go stale when one of the input files of the parser in
+// the directory changes, see parsers.InputFiles.
+func cachedCommands(name string, config parsers.ParserConfig, directory string, parse func() ([]parsers.FormattedCommand, error)) ([]parsers.FormattedCommand, error) {
+	commandCacheMutex.RLock()
+	c := commandCache
+	commandCacheMutex.RUnlock()
+	if c == nil {
+		return parse()
+	}
+
+	if absDir, err := filepath.Abs(directory); err == nil {
+		directory = absDir
+	}
+	key := cache.Key("commands", name, config, directory)
+
+	var commands []parsers.FormattedCommand
+	if c.Get(key, &commands) {
+		return commands, nil
+	}
+
+	commands, err := parse()
+	if err != nil {
+		return nil, err
+	}
+
+	files := parsers.InputFiles(directory, config)
+	// Failing to store only costs parsing again next time
+	c.Put(key, files, commands)
+	return commands, nil
+}
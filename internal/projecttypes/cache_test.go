@@ -0,0 +1,63 @@
+package projecttypes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/martin/go-pm/internal/cache"
+	"github.com/martin/go-pm/internal/parsers"
+)
+
+func TestGetCommandsWithSourceUsesCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	project := filepath.Join(tmpDir, "project")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	taskFile := filepath.Join(project, "Taskfile")
+	if err := os.WriteFile(taskFile, []byte("build\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The parser counts its runs in a file next to the project
+	counter := filepath.Join(tmpDir, "runs")
+	projectType := NewConfigurableProjectType("task", parsers.ParserConfig{
+		DetectFiles:     []string{"Taskfile"},
+		ParserCommand:   "echo run >> " + counter + "; cat Taskfile",
+		CommandTemplate: "task {key}",
+	})
+	runs := func() int {
+		data, _ := os.ReadFile(counter)
+		return len(data) / len("run\n")
+	}
+
+	SetCommandCache(cache.New(filepath.Join(tmpDir, "cache")))
+	defer SetCommandCache(nil)
+
+	for i := 0; i < 2; i++ {
+		commands, err := projectType.GetCommandsWithSource(context.Background(), project)
+		if err != nil {
+			t.Fatalf("GetCommandsWithSource() error = %v", err)
+		}
+		if len(commands) != 1 || commands[0].Command != "task build" {
+			t.Fatalf("GetCommandsWithSource() = %+v", commands)
+		}
+	}
+	if runs() != 1 {
+		t.Errorf("Parser ran %d times, expected once", runs())
+	}
+
+	// Changing the detect file invalidates the entry
+	if err := os.WriteFile(taskFile, []byte("build\ntest\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	commands, err := projectType.GetCommandsWithSource(context.Background(), project)
+	if err != nil {
+		t.Fatalf("GetCommandsWithSource() error = %v", err)
+	}
+	if len(commands) != 2 || runs() != 2 {
+		t.Errorf("Expected a fresh parse with 2 commands, got %+v after %d runs", commands, runs())
+	}
+}
@@ -1,6 +1,7 @@
 package projecttypes
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
@@ -36,15 +37,15 @@ func (c *ConfigurableProjectType) ParseCommands(configPath string) ([]string, er
 	directory := filepath.Dir(configPath)
 	
 	// Parse and format commands using the parser system
-	commands, err := parsers.ParseAndFormatCommands(directory, c.parserConfig)
+	commands, err := parsers.ParseCommandsWithSource(context.Background(), directory, c.parserConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse commands: %w", err)
 	}
 
-	// Convert map to slice for compatibility with existing interface
+	// Keep the parser order for compatibility with existing interface
 	var result []string
-	for key, _ := range commands {
-		result = append(result, key)
+	for _, cmd := range commands {
+		result = append(result, cmd.Key)
 	}
 
 	return result, nil
@@ -86,4 +87,13 @@ func (c *ConfigurableProjectType) CanHandleDirectory(directory string) bool {
 // GetAllCommands returns all commands for a directory as a map
 func (c *ConfigurableProjectType) GetAllCommands(directory string) (map[string]string, error) {
 	return parsers.ParseAndFormatCommands(directory, c.parserConfig)
-}
\ No newline at end of file
+}
+
+// GetCommandsWithSource returns all commands for a directory along with the parser that produced them.
+// They come from the command cache when one is set, see SetCommandCache.
+// Parsing stops early when ctx is cancelled or the parser times out.
+func (c *ConfigurableProjectType) GetCommandsWithSource(ctx context.Context, directory string) ([]parsers.FormattedCommand, error) {
+	return cachedCommands(c.name, c.parserConfig, directory, func() ([]parsers.FormattedCommand, error) {
+		return parsers.ParseCommandsWithSource(ctx, directory, c.parserConfig)
+	})
+}
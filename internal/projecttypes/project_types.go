@@ -3,7 +3,6 @@ package projecttypes
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"sync"
 
 	"github.com/martin/go-pm/internal/parsers"
@@ -29,6 +28,9 @@ var ProjectTypeRegistry = map[string]ProjectType{}
 var registryMutex sync.RWMutex
 var registryInitialized bool
 
+// registryParsers holds the parser configurations the registry was built from
+var registryParsers *parsers.ParsersFile
+
 // initializeRegistry initializes the registry with parsers from configuration
 func initializeRegistry() error {
 	registryMutex.Lock()
@@ -49,6 +51,7 @@ func initializeRegistry() error {
 		ProjectTypeRegistry[name] = NewConfigurableProjectType(name, config)
 	}
 
+	registryParsers = parsersConfig
 	registryInitialized = true
 	return nil
 }
@@ -70,6 +73,7 @@ func GetProjectType(name string) (ProjectType, error) {
 }
 
 // DiscoverProjectType attempts to discover the project type in a directory
+// using the detect_files of the configured parsers
 func DiscoverProjectType(directory string) (ProjectType, error) {
 	if err := initializeRegistry(); err != nil {
 		return nil, err
@@ -78,13 +82,44 @@ func DiscoverProjectType(directory string) (ProjectType, error) {
 	registryMutex.RLock()
 	defer registryMutex.RUnlock()
 
-	for _, projectType := range ProjectTypeRegistry {
-		configFile := filepath.Join(directory, projectType.DetectConfigFile())
-		if fileExists(configFile) {
-			return projectType, nil
+	name, _, err := registryParsers.FindParserForDirectory(directory)
+	if err != nil {
+		return nil, fmt.Errorf("no project type detected in directory: %s", directory)
+	}
+
+	projectType, exists := ProjectTypeRegistry[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown project type: %s", name)
+	}
+
+	return projectType, nil
+}
+
+// DiscoverProjectTypes discovers every project type that applies to a directory,
+// e.g., npm and make for a directory with both package.json and a Makefile
+func DiscoverProjectTypes(directory string) ([]ProjectType, error) {
+	if err := initializeRegistry(); err != nil {
+		return nil, err
+	}
+
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	names, err := registryParsers.FindParsersForDirectory(directory)
+	if err != nil {
+		return nil, fmt.Errorf("no project type detected in directory: %s", directory)
+	}
+
+	var projectTypes []ProjectType
+	for _, name := range names {
+		projectType, exists := ProjectTypeRegistry[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown project type: %s", name)
 		}
+		projectTypes = append(projectTypes, projectType)
 	}
-	return nil, fmt.Errorf("no project type detected in directory: %s", directory)
+
+	return projectTypes, nil
 }
 
 // ListAvailableTypes returns all available project types
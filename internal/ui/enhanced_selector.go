@@ -4,16 +4,20 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 
 	fuzzyfinder "github.com/ktr0731/go-fuzzyfinder"
+	"github.com/martin/go-pm/internal/config"
 )
 
 // SelectionResult represents the result of a user selection
 type SelectionResult struct {
-	Directory   string // The actual directory path where command should be executed
-	Command     string // The command to run
-	DisplayName string // The display name shown in fzf (for reference)
+	Directory   string         // The actual directory path where command should be executed
+	Command     string         // The command to run
+	DisplayName string         // The display name shown in fzf (for reference)
+	LocationID  string         // The id of the location, unique unlike the display name
+	Entry       config.Command // The selected command as configured
 }
 
 // CommandInfo holds information about a command for display
@@ -22,6 +26,62 @@ type CommandInfo struct {
 	Directory   string
 	Command     string
 	DisplayName string
+	LocationID  string
+	Entry       config.Command
+}
+
+// newCommandInfo builds the display information for a command of a location
+func newCommandInfo(location *config.Location, displayName string, command config.Command) CommandInfo {
+	return CommandInfo{
+		Display:     fmt.Sprintf("%s: %s", displayName, command.Title()),
+		Directory:   location.CommandDirectory(command),
+		Command:     command.Run,
+		DisplayName: displayName,
+		LocationID:  location.ID,
+		Entry:       command,
+	}
+}
+
+// selectionResult converts the display information of a command into a selection
+func (info CommandInfo) selectionResult() *SelectionResult {
+	return &SelectionResult{
+		Directory:   info.Directory,
+		Command:     info.Command,
+		DisplayName: info.DisplayName,
+		LocationID:  info.LocationID,
+		Entry:       info.Entry,
+	}
+}
+
+// CommandPreview describes a command for preview windows
+func CommandPreview(directory string, command config.Command) string {
+	var b strings.Builder
+	if command.Name != "" {
+		fmt.Fprintf(&b, "Name: %s\n", command.Name)
+	}
+	if command.Description != "" {
+		fmt.Fprintf(&b, "Description: %s\n", command.Description)
+	}
+	if len(command.Tags) > 0 {
+		fmt.Fprintf(&b, "Tags: %s\n", strings.Join(command.Tags, ", "))
+	}
+	fmt.Fprintf(&b, "Directory: %s\nCommand: %s", directory, command.Run)
+	if command.Shell != "" {
+		fmt.Fprintf(&b, "\nShell: %s", command.Shell)
+	}
+	if len(command.Env) > 0 {
+		keys := make([]string, 0, len(command.Env))
+		for key := range command.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		b.WriteString("\nEnv:")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "\n  %s=%s", key, command.Env[key])
+		}
+	}
+	return b.String()
 }
 
 // EnhancedSelector provides command selection with location filtering
@@ -88,8 +148,7 @@ func (s *EnhancedSelector) runCommandSelector() (*SelectionResult, error) {
 				return "Select this to change location filter\n\nCurrently selected: " + s.getLocationString()
 			}
 			info := commandInfos[i]
-			preview := fmt.Sprintf("Directory: %s\nCommand: %s", info.Directory, info.Command)
-			return preview
+			return CommandPreview(info.Directory, info.Entry)
 		}),
 		fuzzyfinder.WithHeader(s.getHeaderString()),
 	)
@@ -106,37 +165,24 @@ func (s *EnhancedSelector) runCommandSelector() (*SelectionResult, error) {
 		return nil, fmt.Errorf("LOCATION_SELECT")
 	}
 
-	selected := commandInfos[idx]
-	return &SelectionResult{
-		Directory:   selected.Directory,
-		Command:     selected.Command,
-		DisplayName: selected.DisplayName,
-	}, nil
+	return commandInfos[idx].selectionResult(), nil
 }
 
 // prepareFilteredCommands prepares command list filtered by selected locations
 func (s *EnhancedSelector) prepareFilteredCommands() []CommandInfo {
 	var infos []CommandInfo
 
-	for _, location := range s.config.Locations {
+	for i := range s.config.Locations {
+		location := &s.config.Locations[i]
 		// Skip if location is not in selected locations (unless all are selected)
-		if len(s.selectedLocations) > 0 && !s.isLocationSelected(location) {
+		if len(s.selectedLocations) > 0 && !s.isLocationSelected(*location) {
 			continue
 		}
 
-		displayName := location.Name
-		if displayName == "" {
-			displayName = location.Location
-		}
+		displayName := location.DisplayName()
 
 		for _, command := range location.Commands {
-			info := CommandInfo{
-				Display:     fmt.Sprintf("%s: %s", displayName, command),
-				Directory:   location.Location,
-				Command:     command,
-				DisplayName: displayName,
-			}
-			infos = append(infos, info)
+			infos = append(infos, newCommandInfo(location, displayName, command))
 		}
 	}
 
@@ -145,10 +191,7 @@ func (s *EnhancedSelector) prepareFilteredCommands() []CommandInfo {
 
 // isLocationSelected checks if a location is in the selected list
 func (s *EnhancedSelector) isLocationSelected(location config.Location) bool {
-	displayName := location.Name
-	if displayName == "" {
-		displayName = location.Location
-	}
+	displayName := location.DisplayName()
 
 	for _, selected := range s.selectedLocations {
 		if selected == displayName {
@@ -186,10 +229,7 @@ func (s *EnhancedSelector) selectLocations() error {
 	// Prepare location names for selection
 	var locations []string
 	for _, loc := range s.config.Locations {
-		displayName := loc.Name
-		if displayName == "" {
-			displayName = loc.Location
-		}
+		displayName := loc.DisplayName()
 		locations = append(locations, displayName)
 	}
 
@@ -13,16 +13,16 @@ func TestEnhancedSelector_prepareFilteredCommands(t *testing.T) {
 			{
 				Name:     "frontend",
 				Location: "/path/to/frontend",
-				Commands: []string{"npm start", "npm test", "npm build"},
+				Commands: []config.Command{{Run: "npm start"}, {Run: "npm test"}, {Run: "npm build"}},
 			},
 			{
 				Name:     "backend",
 				Location: "/path/to/backend",
-				Commands: []string{"go run main.go", "go test ./..."},
+				Commands: []config.Command{{Run: "go run main.go"}, {Run: "go test ./..."}},
 			},
 			{
 				Location: "/path/to/unnamed",
-				Commands: []string{"make", "make test"},
+				Commands: []config.Command{{Run: "make"}, {Run: "make test"}},
 			},
 		},
 	}
@@ -38,13 +38,13 @@ func TestEnhancedSelector_prepareFilteredCommands(t *testing.T) {
 			selectedLocations: []string{},
 			expectedCount:     7, // 3 + 2 + 2
 			expectedCommands: []string{
-				"[frontend] npm start",
-				"[frontend] npm test",
-				"[frontend] npm build",
-				"[backend] go run main.go",
-				"[backend] go test ./...",
-				"[/path/to/unnamed] make",
-				"[/path/to/unnamed] make test",
+				"frontend: npm start",
+				"frontend: npm test",
+				"frontend: npm build",
+				"backend: go run main.go",
+				"backend: go test ./...",
+				"/path/to/unnamed: make",
+				"/path/to/unnamed: make test",
 			},
 		},
 		{
@@ -52,9 +52,9 @@ func TestEnhancedSelector_prepareFilteredCommands(t *testing.T) {
 			selectedLocations: []string{"frontend"},
 			expectedCount:     3,
 			expectedCommands: []string{
-				"[frontend] npm start",
-				"[frontend] npm test",
-				"[frontend] npm build",
+				"frontend: npm start",
+				"frontend: npm test",
+				"frontend: npm build",
 			},
 		},
 		{
@@ -62,11 +62,11 @@ func TestEnhancedSelector_prepareFilteredCommands(t *testing.T) {
 			selectedLocations: []string{"frontend", "backend"},
 			expectedCount:     5,
 			expectedCommands: []string{
-				"[frontend] npm start",
-				"[frontend] npm test",
-				"[frontend] npm build",
-				"[backend] go run main.go",
-				"[backend] go test ./...",
+				"frontend: npm start",
+				"frontend: npm test",
+				"frontend: npm build",
+				"backend: go run main.go",
+				"backend: go test ./...",
 			},
 		},
 		{
@@ -74,8 +74,8 @@ func TestEnhancedSelector_prepareFilteredCommands(t *testing.T) {
 			selectedLocations: []string{"/path/to/unnamed"},
 			expectedCount:     2,
 			expectedCommands: []string{
-				"[/path/to/unnamed] make",
-				"[/path/to/unnamed] make test",
+				"/path/to/unnamed: make",
+				"/path/to/unnamed: make test",
 			},
 		},
 	}
@@ -222,3 +222,27 @@ func TestEnhancedSelector_isLocationInList(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandPreview(t *testing.T) {
+	plain := CommandPreview("/repo/web", config.Command{Run: "npm start"})
+	if plain != "Directory: /repo/web\nCommand: npm start" {
+		t.Errorf("unexpected preview for plain command: %q", plain)
+	}
+
+	structured := CommandPreview("/repo/web/ui", config.Command{
+		Name:        "storybook",
+		Run:         "npx storybook dev",
+		Description: "Component explorer",
+		Env:         map[string]string{"PORT": "6006", "BROWSER": "none"},
+		Shell:       "bash",
+	})
+	expected := "Name: storybook\nDescription: Component explorer\nDirectory: /repo/web/ui\nCommand: npx storybook dev\nShell: bash\nEnv:\n  BROWSER=none\n  PORT=6006"
+	if structured != expected {
+		t.Errorf("CommandPreview() = %q, expected %q", structured, expected)
+	}
+
+	tagged := CommandPreview("/repo", config.Command{Run: "make e2e", Tags: []string{"test", "slow"}})
+	if tagged != "Tags: test, slow\nDirectory: /repo\nCommand: make e2e" {
+		t.Errorf("unexpected preview for tagged command: %q", tagged)
+	}
+}
@@ -17,8 +17,10 @@ type TUISelector struct {
 	locationList      *tview.List
 	searchInput       *tview.InputField
 	statusText        *tview.TextView
+	warningText       *tview.TextView
+	previewText       *tview.TextView
 	helpText          *tview.TextView
-	selectedLocations map[string]bool
+	selectedLocations map[string]bool // By location id, names may be shared
 	commands          []CommandInfo
 	filteredCommands  []CommandInfo
 	searchQuery       string
@@ -97,6 +99,25 @@ func (s *TUISelector) initUI() {
 		SetTitle(" Filter Status ").
 		SetTitleAlign(tview.AlignLeft)
 
+	// Create the list of problems found while loading the config
+	s.warningText = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(true)
+
+	s.warningText.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Warnings (%d) ", len(s.config.Warnings))).
+		SetTitleAlign(tview.AlignLeft)
+	s.warningText.SetText(s.warningLines())
+
+	// Create preview of the highlighted command
+	s.previewText = tview.NewTextView().
+		SetDynamicColors(false).
+		SetWrap(true)
+
+	s.previewText.SetBorder(true).
+		SetTitle(" Preview ").
+		SetTitleAlign(tview.AlignLeft)
+
 	// Create help text
 	s.helpText = tview.NewTextView().
 		SetDynamicColors(true).
@@ -133,12 +154,7 @@ func (s *TUISelector) initUI() {
 		case tcell.KeyEnter:
 			// If there are filtered commands, select the first one
 			if len(s.filteredCommands) > 0 {
-				cmd := s.filteredCommands[0]
-				s.result = &SelectionResult{
-					Directory:   cmd.Directory,
-					Command:     cmd.Command,
-					DisplayName: cmd.DisplayName,
-				}
+				s.result = s.filteredCommands[0].selectionResult()
 				s.app.Stop()
 			}
 			return nil
@@ -147,14 +163,11 @@ func (s *TUISelector) initUI() {
 	})
 
 	// Populate location list
-	for _, loc := range s.config.Locations {
-		displayName := loc.Name
-		if displayName == "" {
-			displayName = loc.Location
-		}
-		s.locationList.AddItem(displayName, "", 0, nil)
+	for i := range s.config.Locations {
+		loc := &s.config.Locations[i]
+		s.locationList.AddItem(s.locationLabel(loc), "", 0, nil)
 		// Select all locations by default
-		s.selectedLocations[displayName] = true
+		s.selectedLocations[loc.ID] = true
 	}
 
 	// Update location display
@@ -163,16 +176,16 @@ func (s *TUISelector) initUI() {
 	// Set up command selection handler
 	s.commandList.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
 		if index >= 0 && index < len(s.filteredCommands) {
-			cmd := s.filteredCommands[index]
-			s.result = &SelectionResult{
-				Directory:   cmd.Directory,
-				Command:     cmd.Command,
-				DisplayName: cmd.DisplayName,
-			}
+			s.result = s.filteredCommands[index].selectionResult()
 			s.app.Stop()
 		}
 	})
 
+	// Keep the preview in sync with the highlighted command
+	s.commandList.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		s.updatePreview(index)
+	})
+
 	// Set up command list key handler for navigation back to search
 	s.commandList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
@@ -200,15 +213,9 @@ func (s *TUISelector) initUI() {
 		if event.Key() == tcell.KeyRune && event.Rune() == ' ' {
 			currentIndex := s.locationList.GetCurrentItem()
 			if currentIndex >= 0 && currentIndex < len(s.config.Locations) {
-				// Get the actual location display name
-				location := s.config.Locations[currentIndex]
-				displayName := location.Name
-				if displayName == "" {
-					displayName = location.Location
-				}
-
 				// Toggle selection
-				s.selectedLocations[displayName] = !s.selectedLocations[displayName]
+				id := s.config.Locations[currentIndex].ID
+				s.selectedLocations[id] = !s.selectedLocations[id]
 
 				// Update display
 				s.updateLocationDisplay()
@@ -225,11 +232,15 @@ func (s *TUISelector) initUI() {
 		SetDirection(tview.FlexRow).
 		AddItem(s.locationList, 0, 1, false).
 		AddItem(s.statusText, 3, 0, false)
+	if len(s.config.Warnings) > 0 {
+		leftPanel.AddItem(s.warningText, 8, 0, false)
+	}
 
 	rightPanel := tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(s.searchInput, 3, 0, false).
-		AddItem(s.commandList, 0, 1, false)
+		AddItem(s.commandList, 0, 1, false).
+		AddItem(s.previewText, 8, 0, false)
 
 	mainPanel := tview.NewFlex().
 		SetDirection(tview.FlexColumn).
@@ -287,20 +298,12 @@ func (s *TUISelector) initUI() {
 func (s *TUISelector) loadCommands() {
 	s.commands = []CommandInfo{}
 
-	for _, location := range s.config.Locations {
-		displayName := location.Name
-		if displayName == "" {
-			displayName = location.Location
-		}
+	for i := range s.config.Locations {
+		location := &s.config.Locations[i]
+		displayName := location.DisplayName()
 
 		for _, command := range location.Commands {
-			info := CommandInfo{
-				Display:     fmt.Sprintf("%s: %s", displayName, command),
-				Directory:   location.Location,
-				Command:     command,
-				DisplayName: displayName,
-			}
-			s.commands = append(s.commands, info)
+			s.commands = append(s.commands, newCommandInfo(location, displayName, command))
 		}
 	}
 }
@@ -324,7 +327,7 @@ func (s *TUISelector) updateFilteredCommands() {
 		locationFiltered = s.commands
 	} else {
 		for _, cmd := range s.commands {
-			if s.selectedLocations[cmd.DisplayName] {
+			if s.selectedLocations[cmd.LocationID] {
 				locationFiltered = append(locationFiltered, cmd)
 			}
 		}
@@ -344,6 +347,17 @@ func (s *TUISelector) updateFilteredCommands() {
 
 	// Update status
 	s.updateStatus()
+	s.updatePreview(s.commandList.GetCurrentItem())
+}
+
+// updatePreview shows the details of the filtered command at index
+func (s *TUISelector) updatePreview(index int) {
+	if index < 0 || index >= len(s.filteredCommands) {
+		s.previewText.SetText("")
+		return
+	}
+	cmd := s.filteredCommands[index]
+	s.previewText.SetText(CommandPreview(cmd.Directory, cmd.Entry))
 }
 
 // fuzzyFilter performs fuzzy matching on commands
@@ -386,29 +400,46 @@ func (s *TUISelector) fuzzyMatch(text, query string) bool {
 func (s *TUISelector) updateLocationDisplay() {
 	s.locationList.Clear()
 
-	for _, loc := range s.config.Locations {
-		displayName := loc.Name
-		if displayName == "" {
-			displayName = loc.Location
-		}
+	for i := range s.config.Locations {
+		loc := &s.config.Locations[i]
 
 		prefix := "[ ]"
-		if s.selectedLocations[displayName] {
+		if s.selectedLocations[loc.ID] {
 			prefix = "[✓]"
 		}
 
-		s.locationList.AddItem(fmt.Sprintf("%s %s", prefix, displayName), "", 0, nil)
+		s.locationList.AddItem(fmt.Sprintf("%s %s", prefix, s.locationLabel(loc)), "", 0, nil)
 	}
 }
 
+// locationLabel is the display name of a location, followed by its id when
+// other locations share the name
+func (s *TUISelector) locationLabel(location *config.Location) string {
+	displayName := location.DisplayName()
+	if _, shared := s.config.DuplicateNames()[displayName]; shared {
+		return fmt.Sprintf("%s (%s)", displayName, location.ID)
+	}
+	return displayName
+}
+
+// warningLines formats the warnings of the config for the warnings panel
+func (s *TUISelector) warningLines() string {
+	var lines []string
+	for _, warning := range s.config.Warnings {
+		lines = append(lines, "[yellow]![white] "+tview.Escape(warning.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (s *TUISelector) updateStatus() {
 	selectedCount := 0
 	var selectedNames []string
 
-	for name, selected := range s.selectedLocations {
-		if selected {
+	for i := range s.config.Locations {
+		location := &s.config.Locations[i]
+		if s.selectedLocations[location.ID] {
 			selectedCount++
-			selectedNames = append(selectedNames, name)
+			selectedNames = append(selectedNames, s.locationLabel(location))
 		}
 	}
 
@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/martin/go-pm/internal/config"
+)
+
+func TestTUISelectorSelectsLocationsByID(t *testing.T) {
+	cfg := &config.Config{
+		Locations: []config.Location{
+			{Name: "report", ID: "jobs/daily/report", Location: "/repo/jobs/daily/report", Commands: []config.Command{{Run: "./daily.sh"}}},
+			{Name: "report", ID: "jobs/weekly/report", Location: "/repo/jobs/weekly/report", Commands: []config.Command{{Run: "./weekly.sh"}}},
+		},
+	}
+
+	s := NewTUISelector(cfg)
+	s.initUI()
+	s.loadCommands()
+
+	// Unselecting one of the locations named report keeps the other
+	s.selectedLocations["jobs/daily/report"] = false
+	s.updateFilteredCommands()
+
+	if len(s.filteredCommands) != 1 || s.filteredCommands[0].Command != "./weekly.sh" {
+		t.Errorf("filteredCommands = %+v, expected only ./weekly.sh", s.filteredCommands)
+	}
+	if label := s.locationLabel(&cfg.Locations[0]); label != "report (jobs/daily/report)" {
+		t.Errorf("locationLabel() = %q, expected the id after the shared name", label)
+	}
+}